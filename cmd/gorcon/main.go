@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gorcon/rcon-cli/internal/executor"
 )
@@ -12,13 +15,23 @@ import (
 var Version = "develop"
 
 func main() {
+	// Running commands are interrupted on SIGINT and SIGTERM to close
+	// connections and write logs before exit.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	exec := executor.NewExecutor(os.Stdin, os.Stdout, Version)
 
-	if err := exec.Run(os.Args); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		exec.Close()
-		os.Exit(1)
-	}
+	err := exec.Run(ctx, os.Args)
 
+	stop()
 	exec.Close()
+
+	if err != nil {
+		if err.Error() != "" {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		// Exit code depends on the failure class, see ExitCodesHelp.
+		os.Exit(executor.ExitCode(err))
+	}
 }
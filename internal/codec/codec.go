@@ -0,0 +1,66 @@
+// Package codec implements reading and writing of YAML and JSON files
+// shared by configuration, fixture, responses, tokens and schedule files.
+// The format is chosen by file extension.
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedFileExt is returned when file has an unsupported extension.
+// Allowed extensions is `.json`, `.yml`, `.yaml`.
+var ErrUnsupportedFileExt = errors.New("unsupported file extension")
+
+// ReadFile reads the file from disk and decodes its contents into v.
+func ReadFile(name string, v interface{}) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	switch ext := path.Ext(name); ext {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, v)
+	case ".json":
+		err = json.Unmarshal(data, v)
+	default:
+		err = fmt.Errorf("%w %s", ErrUnsupportedFileExt, ext)
+	}
+
+	return err
+}
+
+// WriteFile encodes v and writes it to disk. JSON is indented.
+func WriteFile(name string, v interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch ext := path.Ext(name); ext {
+	case ".yml", ".yaml":
+		data, err = yaml.Marshal(v)
+	case ".json":
+		data, err = json.MarshalIndent(v, "", "  ")
+	default:
+		err = fmt.Errorf("%w %s", ErrUnsupportedFileExt, ext)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	const perm = 0o644
+
+	if err = os.WriteFile(name, data, perm); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package codec_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type document struct {
+	Name  string   `json:"name" yaml:"name"`
+	Items []string `json:"items" yaml:"items"`
+}
+
+func TestReadWriteFile(t *testing.T) {
+	for _, fileName := range []string{"codec-test-local.json", "codec-test-local.yml", "codec-test-local.yaml"} {
+		t.Run(fileName, func(t *testing.T) {
+			defer os.Remove(fileName)
+
+			expected := document{Name: "rust", Items: []string{"status", "players"}}
+			assert.NoError(t, codec.WriteFile(fileName, expected))
+
+			var doc document
+			assert.NoError(t, codec.ReadFile(fileName, &doc))
+			assert.Equal(t, expected, doc)
+		})
+	}
+
+	t.Run("file not exists", func(t *testing.T) {
+		var doc document
+		assert.ErrorIs(t, codec.ReadFile("nonexist.json", &doc), os.ErrNotExist)
+	})
+
+	t.Run("unsupported file extension", func(t *testing.T) {
+		fileName := "codec-test-local.txt"
+		assert.NoError(t, os.WriteFile(fileName, []byte("name: rust"), 0o600))
+		defer os.Remove(fileName)
+
+		var doc document
+		assert.ErrorIs(t, codec.ReadFile(fileName, &doc), codec.ErrUnsupportedFileExt)
+		assert.ErrorIs(t, codec.WriteFile(fileName, doc), codec.ErrUnsupportedFileExt)
+	})
+}
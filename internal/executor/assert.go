@@ -0,0 +1,307 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// Output formats.
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputTable = "table"
+	OutputCSV   = "csv"
+	OutputTSV   = "tsv"
+)
+
+var (
+	// ErrAssertionFailed is returned when command response does not pass
+	// assertions.
+	ErrAssertionFailed = errors.New("assertion failed")
+
+	// ErrUnsupportedOutput is returned when output format is not supported.
+	ErrUnsupportedOutput = errors.New("unsupported output format")
+
+	// ErrInvalidExpectJSON is returned when json assertion is not in
+	// path=regex format.
+	ErrInvalidExpectJSON = errors.New("invalid json assertion: must be path=regex")
+
+	// ErrExpectJSONOutput is returned when json assertions are used without
+	// json output.
+	ErrExpectJSONOutput = errors.New("json assertions require --output json")
+
+	// errPathNotFound is returned when json path does not exist.
+	errPathNotFound = errors.New("path not found")
+)
+
+// Result is the command result printed in json output format. Response is
+// embedded as json if it is valid json, otherwise it is a string.
+type Result struct {
+	Command  string          `json:"command,omitempty"`
+	Response json.RawMessage `json:"response"`
+	Error    string          `json:"error,omitempty"`
+	// Identifier, Type and Stacktrace are WebRCON message metadata.
+	Identifier int    `json:"identifier,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Stacktrace string `json:"stacktrace,omitempty"`
+}
+
+// NewResult creates a new Result.
+func NewResult(command string, response string, err error) Result {
+	result := Result{Command: command}
+
+	if response != "" && json.Valid([]byte(response)) {
+		result.Response = json.RawMessage(response)
+	} else {
+		result.Response, _ = json.Marshal(response)
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// withMessage returns the result with metadata of WebRCON message.
+func (result Result) withMessage(message rconcli.Message) Result {
+	result.Identifier = message.Identifier
+	result.Type = message.Type
+	result.Stacktrace = message.Stacktrace
+
+	return result
+}
+
+// AssertionError describes the difference between expected and actual
+// command response.
+type AssertionError struct {
+	Command string
+	Diff    string
+}
+
+// Error returns the message with the diff.
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("%s: command %q\n%s", ErrAssertionFailed, e.Command, e.Diff)
+}
+
+// Unwrap returns ErrAssertionFailed.
+func (e *AssertionError) Unwrap() error {
+	return ErrAssertionFailed
+}
+
+// JSONAssertion checks the value of json path of the result.
+type JSONAssertion struct {
+	Path  string
+	Regex *regexp.Regexp
+}
+
+// Assertions contains checks of command responses. Expect and ExpectNot
+// are applied to commands by position, a single value is applied to all
+// commands. JSON assertions are applied to all commands.
+type Assertions struct {
+	Expect     []*regexp.Regexp
+	ExpectNot  []*regexp.Regexp
+	ExpectJSON []JSONAssertion
+}
+
+// regexFlag collects values of the repeated flag. Unlike slice flags values
+// are not split by commas, so regular expressions may contain them.
+type regexFlag []string
+
+// Set appends the value.
+func (f *regexFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+// String returns the values separated by spaces.
+func (f *regexFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+// regexValues returns values of the regex flag.
+func regexValues(c *cli.Context, name string) []string {
+	if f, ok := c.Generic(name).(*regexFlag); ok && f != nil {
+		return *f
+	}
+
+	return nil
+}
+
+// newAssertions parses assertions from flags.
+func newAssertions(c *cli.Context) (*Assertions, error) {
+	var (
+		assertions Assertions
+		err        error
+	)
+
+	if assertions.Expect, err = compileAll(regexValues(c, "expect")); err != nil {
+		return nil, fmt.Errorf("expect: %w", err)
+	}
+
+	if assertions.ExpectNot, err = compileAll(regexValues(c, "expect-not")); err != nil {
+		return nil, fmt.Errorf("expect-not: %w", err)
+	}
+
+	for _, value := range regexValues(c, "expect-json") {
+		path, expr, ok := strings.Cut(value, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidExpectJSON, value)
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("expect-json: %w", err)
+		}
+
+		assertions.ExpectJSON = append(assertions.ExpectJSON, JSONAssertion{Path: path, Regex: re})
+	}
+
+	if len(assertions.ExpectJSON) != 0 && c.String("output") != OutputJSON {
+		return nil, ErrExpectJSONOutput
+	}
+
+	return &assertions, nil
+}
+
+// Check returns AssertionError if the response of the command at position
+// does not pass assertions.
+func (a *Assertions) Check(position int, result Result, response string) error {
+	var diffs []string
+
+	if re := byPosition(a.Expect, position); re != nil && !re.MatchString(response) {
+		diffs = append(diffs, fmt.Sprintf("  expected to match:     %s\n  actual response:       %q", re, response))
+	}
+
+	if re := byPosition(a.ExpectNot, position); re != nil && re.MatchString(response) {
+		diffs = append(diffs, fmt.Sprintf("  expected not to match: %s\n  actual response:       %q", re, response))
+	}
+
+	if len(a.ExpectJSON) != 0 {
+		var document interface{}
+
+		data, _ := json.Marshal(result)
+		_ = json.Unmarshal(data, &document)
+
+		for _, assertion := range a.ExpectJSON {
+			value, err := lookupPath(document, assertion.Path)
+			if err != nil {
+				diffs = append(diffs, fmt.Sprintf("  path %s: %v", assertion.Path, err))
+
+				continue
+			}
+
+			if !assertion.Regex.MatchString(value) {
+				diffs = append(diffs, fmt.Sprintf("  path %s expected to match: %s\n  actual value: %q",
+					assertion.Path, assertion.Regex, value))
+			}
+		}
+	}
+
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	return &AssertionError{Command: result.Command, Diff: strings.Join(diffs, "\n")}
+}
+
+// writeResult prints the command result in the output format.
+func writeResult(w io.Writer, output string, result Result, response string) {
+	if output == OutputJSON {
+		data, _ := json.Marshal(result)
+		_, _ = fmt.Fprintln(w, string(data))
+
+		return
+	}
+
+	if response != "" {
+		_, _ = fmt.Fprintln(w, response)
+	}
+}
+
+// compileAll compiles regular expressions. Empty expression is nil to skip
+// the command at its position.
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+
+	for _, expr := range exprs {
+		if expr == "" {
+			compiled = append(compiled, nil)
+
+			continue
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// byPosition returns the expression for the command at position. A single
+// expression is used for all commands.
+func byPosition(exprs []*regexp.Regexp, position int) *regexp.Regexp {
+	switch {
+	case len(exprs) == 1:
+		return exprs[0]
+	case position < len(exprs):
+		return exprs[position]
+	default:
+		return nil
+	}
+}
+
+// lookupPath returns the value of the dot separated path in the json
+// document as a string. Leading "$." and array indexes in brackets are
+// supported, for example "$.response.players[0].name".
+func lookupPath(document interface{}, path string) (string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	value := document
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			v, ok := node[key]
+			if !ok {
+				return "", errPathNotFound
+			}
+
+			value = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", errPathNotFound
+			}
+
+			value = node[i]
+		default:
+			return "", errPathNotFound
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "null", nil
+	default:
+		data, _ := json.Marshal(v)
+
+		return string(data), nil
+	}
+}
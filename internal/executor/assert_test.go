@@ -0,0 +1,104 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertions(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch c.Request().Body() {
+			case "version":
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "Version 41.78.16").WriteTo(c.Conn())
+			case "serverinfo":
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID,
+					`{"Hostname": "Rust Server", "Players": 5, "Queue": [{"Name": "outdead"}]}`).WriteTo(c.Conn())
+			default:
+				handlersRCON(c)
+			}
+		}),
+	)
+	defer serverRCON.Close()
+
+	run := func(args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-a=" + serverRCON.Addr(), "-p=password"}, args...))
+
+		return w.String(), err
+	}
+
+	// Test passed assertions.
+	t.Run("expect", func(t *testing.T) {
+		_, err := run("--expect=41\\.78", "--expect-not=ERROR", "version")
+		assert.NoError(t, err)
+	})
+
+	// Test assertions are applied by position and all failures are reported.
+	t.Run("expect by position", func(t *testing.T) {
+		out, err := run("--expect=41\\.79", "--expect=^help", "--expect=^unknown", "version", "help", "players")
+		assert.ErrorIs(t, err, executor.ErrAssertionFailed)
+		assert.Equal(t, executor.ExitCodeAssertion, executor.ExitCode(err))
+		assert.Contains(t, err.Error(), "assertion failed: command \"version\"\n"+
+			"  expected to match:     41\\.79\n"+
+			"  actual response:       \"Version 41.78.16\"")
+		assert.Contains(t, err.Error(), `command "help"`)
+		assert.NotContains(t, err.Error(), `command "players"`)
+		assert.Contains(t, out, "unknown command")
+	})
+
+	// Test expect not.
+	t.Run("expect not", func(t *testing.T) {
+		_, err := run("--expect-not=^unknown", "players")
+		assert.ErrorIs(t, err, executor.ErrAssertionFailed)
+		assert.Contains(t, err.Error(), "expected not to match: ^unknown")
+	})
+
+	// Test regex with commas is not split.
+	t.Run("expect with comma", func(t *testing.T) {
+		_, err := run("--expect=41\\.\\d{1,3}", "version")
+		assert.NoError(t, err)
+	})
+
+	// Test json output.
+	t.Run("json output", func(t *testing.T) {
+		out, err := run("-o=json", "serverinfo", "version")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"command":"serverinfo","response":{"Hostname":"Rust Server","Players":5,"Queue":[{"Name":"outdead"}]}}`+"\n"+
+			`{"command":"version","response":"Version 41.78.16"}`+"\n", out)
+	})
+
+	// Test json assertions.
+	t.Run("expect json", func(t *testing.T) {
+		_, err := run("-o=json", "--expect-json=response.Players=^5$", "--expect-json=$.response.Queue[0].Name=outdead", "serverinfo")
+		assert.NoError(t, err)
+
+		_, err = run("-o=json", "--expect-json=response.Players=^0$", "--expect-json=response.Missing=.", "serverinfo")
+		assert.ErrorIs(t, err, executor.ErrAssertionFailed)
+		assert.Contains(t, err.Error(), "  path response.Players expected to match: ^0$\n  actual value: \"5\"")
+		assert.Contains(t, err.Error(), "  path response.Missing: path not found")
+	})
+
+	// Test invalid options.
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := run("-o=xml", "version")
+		assert.ErrorIs(t, err, executor.ErrUnsupportedOutput)
+
+		_, err = run("--expect-json=response.Players=5", "serverinfo")
+		assert.ErrorIs(t, err, executor.ErrExpectJSONOutput)
+
+		_, err = run("-o=json", "--expect-json=Players", "serverinfo")
+		assert.ErrorIs(t, err, executor.ErrInvalidExpectJSON)
+	})
+}
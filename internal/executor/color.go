@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+// Color modes.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// colorReset resets terminal color.
+const colorReset = "\033[0m"
+
+// ErrUnsupportedColor is returned when color mode is not supported.
+var ErrUnsupportedColor = errors.New("unsupported color mode")
+
+// messageColors contains terminal colors of WebRCON message types. Generic
+// messages are not colored.
+var messageColors = map[string]string{
+	rconcli.MessageTypeLog:     "\033[90m",
+	rconcli.MessageTypeWarning: "\033[33m",
+	rconcli.MessageTypeError:   "\033[31m",
+	rconcli.MessageTypeChat:    "\033[32m",
+	rconcli.MessageTypeReport:  "\033[36m",
+}
+
+// useColor reports whether output to w is colored in the mode. Auto mode
+// colors terminals unless NO_COLOR environment variable is set.
+func useColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+
+		file, ok := w.(*os.File)
+		if !ok {
+			return false, nil
+		}
+
+		info, err := file.Stat()
+
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("%w %q", ErrUnsupportedColor, mode)
+	}
+}
+
+// colorize wraps the text in the color of the message type.
+func colorize(typ string, text string) string {
+	color, ok := messageColors[typ]
+	if !ok || text == "" {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// formatMessage returns the text of WebRCON message with the stacktrace
+// colored by the message type if colors are enabled.
+func (executor *Executor) formatMessage(typ string, text string, stacktrace string) string {
+	if stacktrace != "" {
+		text += "\n" + stacktrace
+	}
+
+	if !executor.color {
+		return text
+	}
+
+	return colorize(typ, text)
+}
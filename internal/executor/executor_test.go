@@ -2,8 +2,10 @@ package executor_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -13,12 +15,15 @@ import (
 	"time"
 
 	"github.com/gorcon/rcon"
-	"github.com/gorcon/rcon-cli/internal/config"
 	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/fixture"
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/internal/transport/transporttest"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
 	"github.com/gorcon/rcon/rcontest"
 	"github.com/gorcon/telnet"
 	"github.com/gorcon/telnet/telnettest"
-	"github.com/gorcon/websocket"
 	gorilla "github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
@@ -70,7 +75,7 @@ func handlersWebRCON() http.Handler {
 
 		defer ws.Close()
 
-		var response websocket.Message
+		var response webrcon.Message
 
 		// Receive message.
 		_, p, err := ws.ReadMessage()
@@ -81,7 +86,7 @@ func handlersWebRCON() http.Handler {
 			return
 		}
 
-		var message websocket.Message
+		var message webrcon.Message
 		if err := json.Unmarshal(p, &message); err != nil {
 			// TODO: What Rust responses on read message fail?
 			fmt.Println(string(p))
@@ -91,20 +96,20 @@ func handlersWebRCON() http.Handler {
 
 		switch message.Message {
 		case "status":
-			response = websocket.Message{
+			response = webrcon.Message{
 				Message:    MockCommandStatusResponseTextWebRCON,
 				Identifier: message.Identifier,
 				Type:       "Generic",
 			}
 		case "deadline":
-			time.Sleep(websocket.DefaultDeadline + 1*time.Second)
-			response = websocket.Message{
-				Message:    fmt.Sprintf("sleep for %d secends", websocket.DefaultDeadline+1*time.Second),
+			time.Sleep(webrcon.DefaultDeadline + 1*time.Second)
+			response = webrcon.Message{
+				Message:    fmt.Sprintf("sleep for %d secends", webrcon.DefaultDeadline+1*time.Second),
 				Identifier: message.Identifier,
 				Type:       "Generic",
 			}
 		default:
-			response = websocket.Message{
+			response = webrcon.Message{
 				Message:    fmt.Sprintf("Command '%s' not found", message.Message),
 				Identifier: message.Identifier,
 				Type:       "Warning",
@@ -149,7 +154,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: "", Password: "password"}, "help")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: "", Password: "password"}, "help")
 		assert.Error(t, err)
 	})
 
@@ -160,7 +165,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: ""}, "help")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: ""}, "help")
 		assert.Error(t, err)
 	})
 
@@ -171,7 +176,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: "wrong"}, "help")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "wrong"}, "help")
 		assert.Error(t, err)
 	})
 
@@ -182,7 +187,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: "password"}, "")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password"}, "")
 		assert.Error(t, err)
 	})
 
@@ -194,10 +199,30 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: "password"}, string(bigCommand))
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password"}, string(bigCommand))
 		assert.Error(t, err)
 	})
 
+	// Test long response split into packets is printed in full.
+	t.Run("long response", func(t *testing.T) {
+		w := bytes.Buffer{}
+
+		responses := &mock.Responses{Default: strings.Repeat("sv_cheats 0\n", 1000)}
+
+		server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		app := executor.NewExecutor(nil, &w, "")
+		defer app.Close()
+
+		err = app.Execute(context.Background(), &w,
+			&rconcli.Session{Address: server.Addr(), Password: "password", Game: "csgo", ResponseEnd: rconcli.ResponseEndSentinel},
+			"cvarlist")
+		assert.NoError(t, err)
+		assert.Equal(t, responses.Default, w.String())
+	})
+
 	// Positive RCON test Execute func.
 	t.Run("no error rcon", func(t *testing.T) {
 		w := bytes.Buffer{}
@@ -205,7 +230,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: "password"}, "help", "unknown")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password"}, "help", "unknown")
 		assert.NoError(t, err)
 
 		result := strings.TrimSuffix(w.String(), "\n")
@@ -219,7 +244,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverTELNET.Addr(), Password: "password", Type: config.ProtocolTELNET}, "help", "unknown")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverTELNET.Addr(), Password: "password", Type: rconcli.ProtocolTELNET}, "help", "unknown")
 		assert.NoError(t, err)
 
 		result := strings.TrimSuffix(w.String(), "\n")
@@ -235,7 +260,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverWebRCON.Listener.Addr().String(), Password: "password", Type: config.ProtocolWebRCON}, "status")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverWebRCON.Listener.Addr().String(), Password: "password", Type: rconcli.ProtocolWebRCON}, "status")
 		assert.NoError(t, err)
 
 		result := strings.TrimSuffix(w.String(), "\n")
@@ -252,7 +277,7 @@ func TestExecute(t *testing.T) {
 		app := executor.NewExecutor(nil, &w, "")
 		defer app.Close()
 
-		err := app.Execute(&w, &config.Session{Address: serverRCON.Addr(), Password: "password", Log: logFileName}, "help")
+		err := app.Execute(context.Background(), &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password", Log: logFileName}, "help")
 		assert.NoError(t, err)
 	})
 
@@ -310,7 +335,7 @@ func TestExecute(t *testing.T) {
 			app := executor.NewExecutor(nil, &w, "")
 			defer app.Close()
 
-			err := app.Execute(&w, &config.Session{Address: addr, Password: password}, "help")
+			err := app.Execute(context.Background(), &w, &rconcli.Session{Address: addr, Password: password}, "help")
 			assert.NoError(t, err)
 
 			result := strings.TrimSuffix(w.String(), "\n")
@@ -464,7 +489,7 @@ of your current perk levels in a CSV file next to it.
 			app := executor.NewExecutor(nil, &w, "")
 			defer app.Close()
 
-			err := app.Execute(&w, &config.Session{Address: addr, Password: password, Type: config.ProtocolTELNET}, "help")
+			err := app.Execute(context.Background(), &w, &rconcli.Session{Address: addr, Password: password, Type: rconcli.ProtocolTELNET}, "help")
 			assert.NoError(t, err)
 
 			result := strings.TrimSuffix(w.String(), "\n")
@@ -484,7 +509,7 @@ of your current perk levels in a CSV file next to it.
 			app := executor.NewExecutor(nil, &w, "")
 			defer app.Close()
 
-			err := app.Execute(&w, &config.Session{Address: addr, Password: password}, "status")
+			err := app.Execute(context.Background(), &w, &rconcli.Session{Address: addr, Password: password}, "status")
 			assert.NoError(t, err)
 			assert.NotEmpty(t, w.String())
 
@@ -502,7 +527,7 @@ of your current perk levels in a CSV file next to it.
 			app := executor.NewExecutor(nil, &w, "")
 			defer app.Close()
 
-			err := app.Execute(&w, &config.Session{Address: addr, Password: password, Type: config.ProtocolWebRCON}, "status")
+			err := app.Execute(context.Background(), &w, &rconcli.Session{Address: addr, Password: password, Type: rconcli.ProtocolWebRCON}, "status")
 			assert.NoError(t, err)
 			assert.NotEmpty(t, w.String())
 
@@ -540,7 +565,7 @@ func TestInteractive(t *testing.T) {
 		app := executor.NewExecutor(&r, &w, "")
 		defer app.Close()
 
-		err := app.Interactive(&r, &w, &config.Session{Address: serverRCON.Addr(), Password: "fake"})
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "fake"})
 		assert.Error(t, err)
 	})
 
@@ -550,7 +575,7 @@ func TestInteractive(t *testing.T) {
 		r.WriteString("\n")
 		r.WriteString(serverRCON.Addr() + "\n")
 		r.WriteString("password" + "\n")
-		r.WriteString(config.ProtocolRCON + "\n")
+		r.WriteString(rconcli.ProtocolRCON + "\n")
 		r.WriteString(string(make([]byte, 1001)) + "\n")
 		r.WriteString("unknown command" + "\n")
 		r.WriteString(executor.CommandQuit + "\n")
@@ -560,7 +585,7 @@ func TestInteractive(t *testing.T) {
 		app := executor.NewExecutor(&r, &w, "")
 		defer app.Close()
 
-		err := app.Interactive(&r, &w, &config.Session{Address: serverRCON.Addr(), Password: "password"})
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password"})
 		assert.EqualError(t, err, "execute: command too long")
 	})
 
@@ -569,7 +594,7 @@ func TestInteractive(t *testing.T) {
 		r := bytes.Buffer{}
 		r.WriteString(serverRCON.Addr() + "\n")
 		r.WriteString("password" + "\n")
-		r.WriteString(config.ProtocolRCON + "\n")
+		r.WriteString(rconcli.ProtocolRCON + "\n")
 		r.WriteString("help" + "\n")
 		r.WriteString("unknown command" + "\n")
 		r.WriteString(executor.CommandQuit + "\n")
@@ -579,7 +604,7 @@ func TestInteractive(t *testing.T) {
 		app := executor.NewExecutor(&r, &w, "")
 		defer app.Close()
 
-		err := app.Interactive(&r, &w, &config.Session{})
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{})
 		assert.NoError(t, err)
 	})
 
@@ -588,7 +613,7 @@ func TestInteractive(t *testing.T) {
 		r := bytes.Buffer{}
 		r.WriteString(serverTELNET.Addr() + "\n")
 		r.WriteString("password" + "\n")
-		r.WriteString(config.ProtocolTELNET + "\n")
+		r.WriteString(rconcli.ProtocolTELNET + "\n")
 		r.WriteString("help" + "\n")
 		r.WriteString("unknown command" + "\n")
 		r.WriteString(executor.CommandQuit + "\n")
@@ -598,7 +623,7 @@ func TestInteractive(t *testing.T) {
 		app := executor.NewExecutor(&r, &w, "")
 		defer app.Close()
 
-		err := app.Interactive(&r, &w, &config.Session{})
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{})
 		assert.NoError(t, err)
 	})
 
@@ -607,7 +632,7 @@ func TestInteractive(t *testing.T) {
 		r := bytes.Buffer{}
 		r.WriteString(serverWebRCON.Listener.Addr().String() + "\n")
 		r.WriteString("password" + "\n")
-		r.WriteString(config.ProtocolWebRCON + "\n")
+		r.WriteString(rconcli.ProtocolWebRCON + "\n")
 		r.WriteString("status" + "\n")
 		r.WriteString("unknown command" + "\n")
 		r.WriteString(executor.CommandQuit + "\n")
@@ -617,11 +642,54 @@ func TestInteractive(t *testing.T) {
 		app := executor.NewExecutor(&r, &w, "")
 		defer app.Close()
 
-		err := app.Interactive(&r, &w, &config.Session{})
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{})
 		assert.NoError(t, err)
 	})
 }
 
+func TestInteractive_Skip(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "fail" {
+				// Response for another request is returned as command error.
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID+1, "").WriteTo(c.Conn())
+
+				return
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	t.Run("skipped command", func(t *testing.T) {
+		r := bytes.NewBufferString("fail\nhelp\n" + executor.CommandQuit + "\n")
+		w := bytes.Buffer{}
+
+		app := executor.NewExecutor(r, &w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "-s"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "Can I help you?")
+		assert.NotContains(t, w.String(), executor.ErrPartialFailure.Error())
+	})
+
+	t.Run("failed assertion", func(t *testing.T) {
+		r := bytes.NewBufferString("unknown\nhelp\n" + executor.CommandQuit + "\n")
+		w := bytes.Buffer{}
+
+		app := executor.NewExecutor(r, &w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--expect=help"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), executor.ErrAssertionFailed.Error())
+		assert.Contains(t, w.String(), "Can I help you?")
+	})
+}
+
 func TestNewExecutor(t *testing.T) {
 	serverRCON := rcontest.NewServer(
 		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
@@ -642,15 +710,15 @@ func TestNewExecutor(t *testing.T) {
 		args = append(args, "-p="+"password")
 		args = append(args, "help")
 
-		err := app.Run(args)
+		err := app.Run(context.Background(), args)
 		assert.NoError(t, err)
 	})
 
-	// Test getting address and password from config. Log is not used.
+	// Test getting address and password from rconcli. Log is not used.
 	t.Run("getting address and password from args with log", func(t *testing.T) {
 		configFileName := "rcon-test-local.yaml"
 		logFileName := "rcon-test.log"
-		stringBody := fmt.Sprintf(ConfigLayoutYAML, config.DefaultConfigEnv, serverRCON.Addr(), "password", logFileName, "")
+		stringBody := fmt.Sprintf(ConfigLayoutYAML, rconcli.DefaultConfigEnv, serverRCON.Addr(), "password", logFileName, "")
 		createFile(configFileName, stringBody)
 
 		defer func() {
@@ -668,7 +736,7 @@ func TestNewExecutor(t *testing.T) {
 		args = append(args, "-c="+configFileName)
 		args = append(args, "help")
 
-		err := app.Run(args)
+		err := app.Run(context.Background(), args)
 		assert.NoError(t, err)
 	})
 
@@ -676,7 +744,7 @@ func TestNewExecutor(t *testing.T) {
 	t.Run("empty address and password", func(t *testing.T) {
 		configFileName := "rcon-test-local.yaml"
 		logFileName := "rcon-test.log"
-		stringBody := fmt.Sprintf(ConfigLayoutYAML, config.DefaultConfigEnv, "", "", logFileName, "")
+		stringBody := fmt.Sprintf(ConfigLayoutYAML, rconcli.DefaultConfigEnv, "", "", logFileName, "")
 		createFile(configFileName, stringBody)
 
 		defer func() {
@@ -696,7 +764,7 @@ func TestNewExecutor(t *testing.T) {
 		args = append(args, "-c="+configFileName)
 		args = append(args, "help")
 
-		err := app.Run(args)
+		err := app.Run(context.Background(), args)
 		assert.EqualError(t, err, "cli: address is not set: to set address add -a host:port")
 	})
 
@@ -704,7 +772,7 @@ func TestNewExecutor(t *testing.T) {
 	t.Run("empty password", func(t *testing.T) {
 		configFileName := "rcon-test-local.yaml"
 		logFileName := "rcon-test.log"
-		stringBody := fmt.Sprintf(ConfigLayoutYAML, config.DefaultConfigEnv, serverRCON.Addr(), "", logFileName, "")
+		stringBody := fmt.Sprintf(ConfigLayoutYAML, rconcli.DefaultConfigEnv, serverRCON.Addr(), "", logFileName, "")
 		createFile(configFileName, stringBody)
 
 		defer func() {
@@ -725,7 +793,7 @@ func TestNewExecutor(t *testing.T) {
 		args = append(args, "-c="+configFileName)
 		args = append(args, "help")
 
-		err := app.Run(args)
+		err := app.Run(context.Background(), args)
 		assert.EqualError(t, err, "cli: password is not set: to set password add -p password")
 	})
 
@@ -745,7 +813,7 @@ func TestNewExecutor(t *testing.T) {
 		r.WriteString("help" + "\n")
 		r.WriteString(executor.CommandQuit + "\n")
 
-		err := app.Run(args)
+		err := app.Run(context.Background(), args)
 		assert.NoError(t, err)
 	})
 }
@@ -769,3 +837,211 @@ func createFile(name, stringBody string) error {
 
 	return err
 }
+
+func TestRecordPlayback(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	fixtureFileName := "rcon-test-fixture.json"
+	defer os.Remove(fixtureFileName)
+
+	// Test record responses from remote server.
+	t.Run("record", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--record=" + fixtureFileName, "help", "unknown"})
+		assert.NoError(t, err)
+		assert.NoError(t, app.Close())
+	})
+
+	// Test play back responses without remote server.
+	t.Run("playback", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=127.0.0.1:0", "-p=password", "--playback=" + fixtureFileName, "help", "unknown"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Can I help you?\n"+executor.CommandsResponseSeparator+"\nunknown command\n", w.String())
+
+		err = app.Run(context.Background(), []string{"", "-a=127.0.0.1:0", "-p=password", "--playback=" + fixtureFileName, "help"})
+		assert.ErrorIs(t, err, fixture.ErrNoInteraction)
+	})
+
+	// Test fixture is not played back in session of another protocol.
+	t.Run("protocol mismatch", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=127.0.0.1:0", "-p=password", "-t=web",
+			"--playback=" + fixtureFileName, "--playback-latency", "help"})
+		assert.ErrorIs(t, err, fixture.ErrProtocolMismatch)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+}
+
+func TestDeadline(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "sleep" {
+				time.Sleep(time.Second)
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	// Test overall deadline interrupts the hung command.
+	t.Run("deadline exceeded", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		logFileName := "rcon-deadline-test-local.log"
+		defer os.Remove(logFileName)
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "-l=" + logFileName,
+			"--deadline=100ms", "help", "sleep", "help"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "Can I help you?\n"+executor.CommandsResponseSeparator+"\n", w.String())
+
+		// Interrupted command is logged with the error.
+		records, err := rconcli.ReadLog(logFileName)
+		assert.NoError(t, err)
+
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, "sleep", records[1].Request)
+			assert.Equal(t, "interrupted: "+context.DeadlineExceeded.Error(), records[1].Response)
+		}
+	})
+
+	// Test interactive mode returns when the context is canceled.
+	t.Run("interactive canceled", func(t *testing.T) {
+		r, pw := io.Pipe()
+		defer pw.Close()
+
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := app.Interactive(ctx, r, w, &rconcli.Session{Address: serverRCON.Addr(), Password: "password", Type: rconcli.ProtocolRCON})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestCommandTimeout(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "sleep" {
+				time.Sleep(500 * time.Millisecond)
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	// Test timed out command fails.
+	t.Run("command timeout", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--command-timeout=50ms", "sleep"})
+		assert.ErrorIs(t, err, rconcli.ErrCommandTimeout)
+		assert.Equal(t, executor.ExitCodeTimeout, executor.ExitCode(err))
+	})
+
+	// Test connection is reopened after timed out command.
+	t.Run("reconnect after timeout", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "-s", "--command-timeout=50ms", "sleep", "help"})
+		assert.ErrorIs(t, err, executor.ErrPartialFailure)
+		assert.Equal(t, executor.ExitCodePartial, executor.ExitCode(err))
+		assert.Contains(t, w.String(), "execute: command timeout after 50ms")
+		assert.Contains(t, w.String(), "Can I help you?")
+	})
+}
+
+func TestInteractive_Tunnel(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	serverTELNET := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetCommandHandler(handlersTELNET),
+	)
+	defer serverTELNET.Close()
+
+	bastion, err := transporttest.NewBastion()
+	assert.NoError(t, err)
+	defer bastion.Close()
+
+	// Test commands are executed through one SSH connection.
+	t.Run("rcon", func(t *testing.T) {
+		r := bytes.Buffer{}
+		r.WriteString("help\nhelp\n" + executor.CommandQuit + "\n")
+
+		w := bytes.Buffer{}
+
+		app := executor.NewExecutor(&r, &w, "")
+		defer app.Close()
+
+		connections := bastion.Connections()
+
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{
+			Address:       serverRCON.Addr(),
+			Password:      "password",
+			Type:          rconcli.ProtocolRCON,
+			Via:           bastion.URL(),
+			ViaKey:        bastion.KeyFile,
+			ViaKnownHosts: bastion.KnownHostsFile,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(w.String(), "Can I help you?"))
+		assert.Equal(t, connections+1, bastion.Connections())
+	})
+
+	t.Run("telnet", func(t *testing.T) {
+		r := bytes.Buffer{}
+		r.WriteString("help\n" + executor.CommandQuit + "\n")
+
+		w := bytes.Buffer{}
+
+		app := executor.NewExecutor(&r, &w, "")
+		defer app.Close()
+
+		err := app.Interactive(context.Background(), &r, &w, &rconcli.Session{
+			Address:       serverTELNET.Addr(),
+			Password:      "password",
+			Type:          rconcli.ProtocolTELNET,
+			Via:           bastion.URL(),
+			ViaKey:        bastion.KeyFile,
+			ViaKnownHosts: bastion.KnownHostsFile,
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "Can I help you?")
+	})
+}
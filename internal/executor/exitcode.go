@@ -0,0 +1,183 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/gorcon/rcon-cli/internal/fixture"
+	"github.com/gorcon/rcon-cli/internal/table"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/telnet"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes returned by the CLI. Ping command uses its own Nagios
+// compatible codes instead.
+const (
+	// ExitCodeOK is the exit code when all commands succeed.
+	ExitCodeOK = 0
+
+	// ExitCodeFailure is the exit code for errors without specific exit code.
+	ExitCodeFailure = 1
+
+	// ExitCodeUsage is the exit code for invalid flags and arguments.
+	ExitCodeUsage = 2
+
+	// ExitCodeConfig is the exit code when the config file cannot be read
+	// or is invalid.
+	ExitCodeConfig = 3
+
+	// ExitCodeNetwork is the exit code when remote server is unreachable
+	// or the connection is lost.
+	ExitCodeNetwork = 4
+
+	// ExitCodeAuth is the exit code when remote server rejects the password.
+	ExitCodeAuth = 5
+
+	// ExitCodeTimeout is the exit code when dial, command or deadline
+	// timeout is exceeded.
+	ExitCodeTimeout = 6
+
+	// ExitCodeCommand is the exit code when remote server returns an error
+	// for the command.
+	ExitCodeCommand = 7
+
+	// ExitCodePartial is the exit code when some commands failed and were
+	// skipped with --skip flag.
+	ExitCodePartial = 8
+
+	// ExitCodeDenied is the exit code when command is refused by the deny list
+	// or is not confirmed.
+	ExitCodeDenied = 9
+
+	// ExitCodeAssertion is the exit code when command response does not pass
+	// --expect, --expect-not or --expect-json assertions.
+	ExitCodeAssertion = 10
+)
+
+// ExitCodesHelp describes exit codes in the CLI help.
+const ExitCodesHelp = "Exit codes: \n" +
+	"   0   all commands succeeded \n" +
+	"   1   general error \n" +
+	"   2   invalid flags or arguments \n" +
+	"   3   config file cannot be read or is invalid \n" +
+	"   4   network error, remote server is unreachable or the connection is lost \n" +
+	"   5   authentication failed \n" +
+	"   6   dial, command or deadline timeout exceeded \n" +
+	"   7   remote server returned an error for the command \n" +
+	"   8   partial failure, some commands failed and were skipped with --skip \n" +
+	"   9   command is denied or not confirmed \n" +
+	"   10  command response does not pass assertions \n\n" +
+	"Ping command exits with Nagios compatible codes 0-3 instead."
+
+// ErrPartialFailure is returned when some commands failed and were skipped.
+var ErrPartialFailure = errors.New("partial failure")
+
+// ExitError is returned when the CLI must exit with the specific code.
+// If Err is nil, the error is silent and nothing is printed on exit.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the wrapped error.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// ExitCode returns the exit code for the error returned from Run. Errors
+// without specific exit code are classified by their cause.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return classify(err, ExitCodeFailure)
+}
+
+// classify returns the exit code for the error cause or fallback code if
+// the cause is unknown. Timeouts are checked first because network timeouts
+// are network errors too.
+func classify(err error, fallback int) int {
+	var (
+		netErr  net.Error
+		certErr *tls.CertificateVerificationError
+	)
+
+	switch {
+	case isAny(err, ErrEmptyAddress, ErrEmptyPassword, ErrCommandEmpty, ErrUnsupportedOutput,
+		ErrInvalidExpectJSON, ErrExpectJSONOutput, ErrTableOutput, ErrUnsupportedColor, ErrUnknownRole,
+		ErrInvalidPlan, ErrDryRunNotSupported, ErrEmptyReplayFile, ErrEmptyScheduleFile, ErrEmptyShutdown,
+		ErrEmptyGame, ErrEmptyTokens,
+		rconcli.ErrUnsupportedProtocol, rconcli.ErrFollowNotSupported, rconcli.ErrUnsupportedWebScheme,
+		rconcli.ErrInsecureWebScheme, rconcli.ErrNoAddress, rconcli.ErrUnsupportedTunnelScheme,
+		rconcli.ErrInvalidAddress, rconcli.ErrUnsupportedResponseEnd, table.ErrUnknownColumn,
+		fixture.ErrProtocolMismatch):
+		return ExitCodeUsage
+	case isAny(err, rconcli.ErrConfigValidation, rconcli.ErrUnsupportedFileExt, rconcli.ErrInvalidCA):
+		return ExitCodeConfig
+	case isAny(err, rconcli.ErrDialTimeout, rconcli.ErrCommandTimeout, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ExitCodeTimeout
+	// Interactive TELNET mode dials with the telnet package directly.
+	case isAny(err, rconcli.ErrAuthFailed, telnet.ErrAuthFailed, telnet.ErrAuthUnexpectedMessage):
+		return ExitCodeAuth
+	case errors.As(err, &netErr), errors.As(err, &certErr),
+		isAny(err, io.EOF, io.ErrUnexpectedEOF, rconcli.ErrNoSRVRecords):
+		return ExitCodeNetwork
+	}
+
+	return fallback
+}
+
+// isAny reports whether any error in err's tree matches any of targets.
+func isAny(err error, targets ...error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// partialFailure returns partial failure error if any of total commands
+// failed and was skipped.
+func partialFailure(skipped, total int) error {
+	if skipped == 0 {
+		return nil
+	}
+
+	return &ExitError{
+		Code: ExitCodePartial,
+		Err:  fmt.Errorf("execute: %w: %d of %d commands failed", ErrPartialFailure, skipped, total),
+	}
+}
+
+// usageError returns flag parsing errors with usage exit code.
+func usageError(_ *cli.Context, err error, _ bool) error {
+	return &ExitError{Code: ExitCodeUsage, Err: err}
+}
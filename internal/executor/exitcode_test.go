@@ -0,0 +1,98 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, executor.ExitCodeOK},
+		{"unknown", errors.New("unknown"), executor.ExitCodeFailure},
+		{"exit error", fmt.Errorf("cli: %w", &executor.ExitError{Code: executor.ExitCodeDenied}), executor.ExitCodeDenied},
+		{"usage", fmt.Errorf("cli: %w", executor.ErrEmptyAddress), executor.ExitCodeUsage},
+		{"config", fmt.Errorf("config: %w", rconcli.ErrConfigValidation), executor.ExitCodeConfig},
+		{"network", fmt.Errorf("auth: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), executor.ExitCodeNetwork},
+		{"connection lost", fmt.Errorf("execute: %w", io.EOF), executor.ExitCodeNetwork},
+		{"certificate", fmt.Errorf("tls: %w", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), executor.ExitCodeNetwork},
+		{"ca file", fmt.Errorf("tls: %w", rconcli.ErrInvalidCA), executor.ExitCodeConfig},
+		{"invalid address", fmt.Errorf("plan: %w", rconcli.ErrInvalidAddress), executor.ExitCodeUsage},
+		{"response end", fmt.Errorf("rcon: %w", rconcli.ErrUnsupportedResponseEnd), executor.ExitCodeUsage},
+		{"no srv records", fmt.Errorf("srv: %w", rconcli.ErrNoSRVRecords), executor.ExitCodeNetwork},
+		{"auth", fmt.Errorf("auth: %w", rconcli.ErrAuthFailed), executor.ExitCodeAuth},
+		{"timeout", fmt.Errorf("auth: %w", rconcli.ErrDialTimeout), executor.ExitCodeTimeout},
+		{"deadline", fmt.Errorf("execute: %w", context.DeadlineExceeded), executor.ExitCodeTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, executor.ExitCode(test.err))
+		})
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "fail" {
+				// Response for another request is returned as command error.
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID+1, "").WriteTo(c.Conn())
+
+				return
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	closedAddr := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"ok", []string{"-a=" + serverRCON.Addr(), "-p=password", "help"}, executor.ExitCodeOK},
+		{"unknown flag", []string{"--unknown", "help"}, executor.ExitCodeUsage},
+		{"unknown subcommand flag", []string{"wait", "--unknown"}, executor.ExitCodeUsage},
+		{"unknown ping flag", []string{"ping", "--unknown"}, executor.PingUnknown},
+		{"config not found", []string{"-c=nonexist.yaml", "help"}, executor.ExitCodeConfig},
+		{"network", []string{"-a=" + closedAddr, "-p=password", "help"}, executor.ExitCodeNetwork},
+		{"auth", []string{"-a=" + serverRCON.Addr(), "-p=wrong", "help"}, executor.ExitCodeAuth},
+		{"command", []string{"-a=" + serverRCON.Addr(), "-p=password", "fail", "help"}, executor.ExitCodeCommand},
+		{"partial", []string{"-a=" + serverRCON.Addr(), "-p=password", "-s", "fail", "help"}, executor.ExitCodePartial},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+			defer app.Close()
+
+			err := app.Run(context.Background(), append([]string{""}, test.args...))
+			assert.Equal(t, test.want, executor.ExitCode(err), err)
+		})
+	}
+}
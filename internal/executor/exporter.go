@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorcon/rcon-cli/internal/exporter"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultExporterAddress is the default address for exporter to listen on.
+const DefaultExporterAddress = ":9090"
+
+// exporterCommand returns the subcommand starting Prometheus exporter.
+func (executor *Executor) exporterCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "exporter",
+		Usage: "Expose metrics from command responses on /metrics endpoint for Prometheus",
+		Description: "Periodically executes commands from metrics section of environments and extracts gauges " +
+			"values with regular expressions. Environments passed as arguments are collected, all environments " +
+			"from the configuration file are collected by default.",
+		ArgsUsage: "[environments...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "Set host and port to listen on",
+				Value: DefaultExporterAddress,
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Set interval between metrics collections",
+				Value: exporter.DefaultInterval,
+			},
+		},
+		Action: executor.exporter,
+	}
+}
+
+// exporter executes when exporter subcommand is specified.
+func (executor *Executor) exporter(c *cli.Context) error {
+	if isDryRun(c) {
+		return fmt.Errorf("exporter: %w", ErrDryRunNotSupported)
+	}
+
+	targets, err := executor.exporterTargets(c)
+	if err != nil {
+		return err
+	}
+
+	exp, err := exporter.New(targets, func(ses *rconcli.Session) (rconcli.Client, error) {
+		return executor.dialClient(c.Context, ses)
+	})
+	if err != nil {
+		return fmt.Errorf("exporter: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp)
+
+	server := &http.Server{Addr: c.String("listen"), Handler: mux, ReadHeaderTimeout: rconcli.DefaultTimeout}
+
+	ctx, cancel := context.WithCancel(c.Context)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		exp.Run(ctx, c.Duration("interval"))
+	}()
+
+	// Connections are closed after the running collection returns.
+	defer func() {
+		cancel()
+		<-done
+		exp.Close()
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		_ = server.Close()
+	}()
+
+	_, _ = fmt.Fprintf(executor.w, "Exporter is listening on %s (press ^C to stop)\n", server.Addr)
+
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("exporter: %w", err)
+	}
+
+	return nil
+}
+
+// exporterTargets returns sessions of environments to collect.
+func (executor *Executor) exporterTargets(c *cli.Context) ([]*exporter.Target, error) {
+	sessions, err := executor.envSessions(c)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*exporter.Target, 0, len(sessions))
+	for env, ses := range sessions {
+		targets = append(targets, &exporter.Target{Env: env, Session: ses})
+	}
+
+	return targets, nil
+}
+
+// envSessions returns sessions of environments passed as arguments or of
+// all environments from the config file if there are no arguments.
+func (executor *Executor) envSessions(c *cli.Context) (map[string]*rconcli.Session, error) {
+	envs := c.Args().Slice()
+
+	if len(envs) == 0 {
+		var err error
+		if envs, err = executor.configEnvs(c); err != nil {
+			return nil, err
+		}
+	}
+
+	sessions := make(map[string]*rconcli.Session, len(envs))
+
+	for _, env := range envs {
+		ses, err := executor.newSession(c, env)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ses.HasAddress() {
+			return nil, fmt.Errorf("%s: %w", env, ErrEmptyAddress)
+		}
+
+		sessions[env] = ses
+	}
+
+	return sessions, nil
+}
+
+// dialClient creates a new authorized connection to remote server which is
+// not bound to the executor.
+func (executor *Executor) dialClient(ctx context.Context, ses *rconcli.Session) (rconcli.Client, error) {
+	client := NewExecutor(nil, io.Discard, executor.version)
+	if err := client.Dial(ctx, ses); err != nil {
+		return nil, err
+	}
+
+	return client.client, nil
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// errFollowDone stops following when the count of messages is printed.
+var errFollowDone = errors.New("follow done")
+
+// followCommand returns the subcommand printing messages of WebRCON server
+// as they arrive.
+func (executor *Executor) followCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "follow",
+		Usage: "Print logs, chat and other messages of WebRCON server as they arrive",
+		Description: "Messages are printed with their type and colored by type in terminal. With --output json each " +
+			"message is printed as json line with identifier, type and stacktrace. Following stops on ^C or " +
+			"when --deadline is exceeded.",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "message-type",
+				Usage: "Print only messages of the types, e.g. Chat,Error",
+			},
+			&cli.IntFlag{
+				Name:  "count",
+				Usage: "Stop after the number of printed messages. Zero means follow until interrupted",
+			},
+		},
+		Action: executor.follow,
+	}
+}
+
+// follow executes when follow subcommand is specified.
+func (executor *Executor) follow(c *cli.Context) error {
+	ses, err := executor.NewSession(c)
+	if err != nil {
+		return err
+	}
+
+	if !ses.HasAddress() {
+		return ErrEmptyAddress
+	}
+
+	if ses.Password == "" {
+		return ErrEmptyPassword
+	}
+
+	types := map[string]bool{}
+
+	for _, typ := range c.StringSlice("message-type") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			types[strings.ToLower(typ)] = true
+		}
+	}
+
+	if isDryRun(c) {
+		return executor.dryRun(c, ses, nil, "none, server messages are followed")
+	}
+
+	count, printed := c.Int("count"), 0
+
+	err = rconcli.Follow(c.Context, ses, func(message rconcli.Message) error {
+		if len(types) != 0 && !types[strings.ToLower(message.Type)] {
+			return nil
+		}
+
+		executor.printMessage(message)
+
+		if printed++; count > 0 && printed >= count {
+			return errFollowDone
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFollowDone) {
+		return fmt.Errorf("follow: %w", err)
+	}
+
+	return nil
+}
+
+// printMessage prints the message received in follow mode.
+func (executor *Executor) printMessage(message rconcli.Message) {
+	if executor.output == OutputJSON {
+		data, _ := json.Marshal(NewResult("", message.Message, nil).withMessage(message))
+		_, _ = fmt.Fprintln(executor.w, string(data))
+
+		return
+	}
+
+	text := fmt.Sprintf("[%s] %s", message.Type, strings.TrimSpace(message.Message))
+
+	_, _ = fmt.Fprintln(executor.w, executor.formatMessage(message.Type, text, message.Stacktrace))
+}
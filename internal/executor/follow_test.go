@@ -0,0 +1,140 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFollowServer starts WebRCON server sending log, chat and error messages
+// on connect and replying to commands with warnings.
+func newFollowServer() *httptest.Server {
+	upgrader := gorilla.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		for _, message := range []webrcon.Message{
+			{Message: "Saved 100 ents", Type: webrcon.TypeLog},
+			{Message: `{"Message":"hi","Username":"Player"}`, Identifier: -1, Type: webrcon.TypeChat},
+			{Message: "NullReferenceException", Type: webrcon.TypeError, Stacktrace: "at Foo()"},
+		} {
+			_ = ws.WriteJSON(message)
+		}
+
+		for {
+			var request webrcon.Message
+			if err := ws.ReadJSON(&request); err != nil {
+				return
+			}
+
+			_ = ws.WriteJSON(webrcon.Message{
+				Message: "Command '" + request.Message + "' not found", Identifier: request.Identifier, Type: webrcon.TypeWarning,
+			})
+		}
+	}))
+}
+
+func TestFollow(t *testing.T) {
+	server := newFollowServer()
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	run := func(args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-a=" + addr, "-p=password", "-t=web"}, args...))
+
+		return w.String(), err
+	}
+
+	t.Run("text", func(t *testing.T) {
+		out, err := run("follow", "--count=3")
+		assert.NoError(t, err)
+		assert.Equal(t, "[Log] Saved 100 ents\n"+
+			"[Chat] {\"Message\":\"hi\",\"Username\":\"Player\"}\n"+
+			"[Error] NullReferenceException\nat Foo()\n", out)
+	})
+
+	t.Run("json and type filter", func(t *testing.T) {
+		out, err := run("-o=json", "follow", "--message-type=chat,error", "--count=2")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"response":{"Message":"hi","Username":"Player"},"identifier":-1,"type":"Chat"}`+"\n"+
+			`{"response":"NullReferenceException","type":"Error","stacktrace":"at Foo()"}`+"\n", out)
+	})
+
+	t.Run("color", func(t *testing.T) {
+		out, err := run("--color=always", "follow", "--count=1")
+		assert.NoError(t, err)
+		assert.Equal(t, "\033[90m[Log] Saved 100 ents\033[0m\n", out)
+	})
+
+	// Test deadline stops following without error.
+	t.Run("deadline", func(t *testing.T) {
+		out, err := run("--deadline=200ms", "follow")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out, "[Log] Saved 100 ents\n"))
+		assert.Contains(t, out, "[Error] NullReferenceException")
+	})
+
+	t.Run("not web", func(t *testing.T) {
+		_, err := run("-t=rcon", "follow")
+		assert.ErrorIs(t, err, rconcli.ErrFollowNotSupported)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+
+	t.Run("unsupported color", func(t *testing.T) {
+		_, err := run("--color=rainbow", "follow")
+		assert.ErrorIs(t, err, executor.ErrUnsupportedColor)
+	})
+}
+
+// Test WebRCON metadata is printed in json output and messages are colored
+// by type.
+func TestExecute_WebRCONMessage(t *testing.T) {
+	server := newFollowServer()
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"-o=json", "--web-identifier=42", "status"},
+			want: `{"command":"status","response":"Command 'status' not found","identifier":42,"type":"Warning"}` + "\n",
+		},
+		{
+			args: []string{"--color=always", "status"},
+			want: "\033[33mCommand 'status' not found\033[0m\n",
+		},
+	} {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+
+		err := app.Run(context.Background(), append([]string{"", "-a=" + addr, "-p=password", "-t=web", "--web-name=bot"}, test.args...))
+		assert.NoError(t, err, test.args)
+		assert.Equal(t, test.want, w.String(), test.args)
+
+		app.Close()
+	}
+}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/internal/table"
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultMaxWidth is the default maximum width of table cells.
+const DefaultMaxWidth = 40
+
+// ErrTableOutput is returned when table options are used without table
+// output.
+var ErrTableOutput = errors.New("table options require --output table, csv or tsv")
+
+// TableFormat contains options of rendering tabular responses.
+type TableFormat struct {
+	// Header matches the header line. Header is detected if it is nil.
+	Header  *regexp.Regexp
+	Columns []string
+	// Sort is the column to sort rows by, prefixed with minus for
+	// descending order.
+	Sort string
+	// MaxWidth truncates longer cells in table output. Zero does not
+	// truncate.
+	MaxWidth int
+}
+
+// isTableOutput reports whether the output format renders tables.
+func isTableOutput(output string) bool {
+	return output == OutputTable || output == OutputCSV || output == OutputTSV
+}
+
+// isMachineReadable reports whether the output format is parsed by scripts
+// and must not contain separators.
+func isMachineReadable(output string) bool {
+	return output == OutputJSON || output == OutputCSV || output == OutputTSV
+}
+
+// newTableFormat parses table options from flags.
+func newTableFormat(c *cli.Context, output string) (*TableFormat, error) {
+	format := &TableFormat{
+		Sort:     c.String("sort"),
+		MaxWidth: c.Int("max-width"),
+	}
+
+	for _, column := range c.StringSlice("columns") {
+		if column = strings.TrimSpace(column); column != "" {
+			format.Columns = append(format.Columns, column)
+		}
+	}
+
+	if expr := c.String("table-header"); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("table-header: %w", err)
+		}
+
+		format.Header = re
+	}
+
+	if !isTableOutput(output) && (format.Header != nil || len(format.Columns) != 0 || format.Sort != "") {
+		return nil, ErrTableOutput
+	}
+
+	return format, nil
+}
+
+// Write renders the table found in the response. Response without table
+// is printed as is in table output and skipped in csv and tsv outputs.
+func (format *TableFormat) Write(w io.Writer, output string, response string) error {
+	tbl, err := table.Parse(response, format.Header)
+	if errors.Is(err, table.ErrNoTable) && output == OutputTable {
+		if response != "" {
+			_, _ = fmt.Fprintln(w, response)
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return format.render(w, output, tbl)
+}
+
+// render sorts the table, selects columns and prints the table in
+// the output format.
+func (format *TableFormat) render(w io.Writer, output string, tbl *table.Table) error {
+	// Rows are sorted before selection to sort by not selected column.
+	if format.Sort != "" {
+		if err := tbl.Sort(format.Sort); err != nil {
+			return err
+		}
+	}
+
+	if len(format.Columns) != 0 {
+		if err := tbl.Select(format.Columns...); err != nil {
+			return err
+		}
+	}
+
+	switch output {
+	case OutputCSV:
+		return tbl.WriteCSV(w, ',')
+	case OutputTSV:
+		return tbl.WriteCSV(w, '\t')
+	default:
+		return tbl.Write(w, format.MaxWidth)
+	}
+}
@@ -0,0 +1,98 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/table"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockStatusTable = `hostname: Rust Server [DOCKER]
+players : 2 (500 max) (0 queued) (0 joining)
+id                name        ping connected addr
+76561198000000001 "Player"    52   102.45s   203.0.113.5:56789
+76561198000000002 "Night Owl" 120  3600s     203.0.113.6:1234`
+
+func TestTableFormat(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "status" {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, mockStatusTable).WriteTo(c.Conn())
+
+				return
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	run := func(args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-a=" + serverRCON.Addr(), "-p=password"}, args...))
+
+		return w.String(), err
+	}
+
+	t.Run("table", func(t *testing.T) {
+		out, err := run("--output=table", "--columns=name,ping", "--sort=-ping", "--max-width=5", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "hostname: Rust Server [DOCKER]\n"+
+			"players : 2 (500 max) (0 queued) (0 joining)\n"+
+			"name   ping\n"+
+			"Nigh…  120\n"+
+			"Play…  52\n", out)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		out, err := run("--output=csv", "--columns=id,name", "status", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "id,name\n76561198000000001,Player\n76561198000000002,Night Owl\n"+
+			"id,name\n76561198000000001,Player\n76561198000000002,Night Owl\n", out)
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		out, err := run("-o=tsv", "--columns=name", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "name\nPlayer\nNight Owl\n", out)
+	})
+
+	// Test not tabular response is printed as is in table output.
+	t.Run("not table", func(t *testing.T) {
+		out, err := run("--output=table", "help")
+		assert.NoError(t, err)
+		assert.Equal(t, "Can I help you?\n", out)
+
+		// Error is written to stderr, not to the csv stream.
+		out, err = run("--output=csv", "help")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		out, err := run("--output=table", "--columns=score", "status")
+		assert.ErrorIs(t, err, table.ErrUnknownColumn)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+		assert.NotContains(t, out, "unknown column")
+
+		_, err = run("--output=csv", "--sort=score", "status")
+		assert.ErrorIs(t, err, table.ErrUnknownColumn)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+
+	t.Run("table options without table output", func(t *testing.T) {
+		_, err := run("--columns=name", "status")
+		assert.ErrorIs(t, err, executor.ErrTableOutput)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+}
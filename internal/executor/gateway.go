@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorcon/rcon-cli/internal/gateway"
+	"github.com/gorcon/rcon-cli/internal/pool"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultGatewayAddress is the default address for gateway to listen on.
+const DefaultGatewayAddress = ":8080"
+
+// ErrEmptyTokens is returned when gateway is started without tokens file.
+var ErrEmptyTokens = errors.New("tokens file is not set: to set tokens file add --tokens path/to/tokens.yaml")
+
+// gatewayCommand returns the subcommand starting HTTP gateway.
+func (executor *Executor) gatewayCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "gateway",
+		Usage: "Start HTTP gateway executing commands on remote servers",
+		Description: "Exposes POST /v1/envs/{env}/exec, GET /v1/envs and GET /v1/envs/{env}/console websocket " +
+			"endpoints. Requests are authorized with bearer tokens from the tokens file. Environments passed as " +
+			"arguments are served, all environments from the configuration file are served by default.",
+		ArgsUsage: "[environments...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "Set host and port to listen on",
+				Value: DefaultGatewayAddress,
+			},
+			&cli.StringFlag{
+				Name:  "tokens",
+				Usage: "Path to the file with bearer tokens and allowed environments",
+			},
+			&cli.IntFlag{
+				Name:  "max-conns",
+				Usage: "Set max number of open connections, least recently used idle connection is closed",
+			},
+			&cli.DurationFlag{
+				Name:  "idle-timeout",
+				Usage: "Close connections unused for the duration",
+			},
+			&cli.StringFlag{
+				Name:  "health-check",
+				Usage: "Set command executed to check idle connections",
+			},
+			&cli.DurationFlag{
+				Name:  "health-check-interval",
+				Usage: "Set interval between idle connections checks",
+				Value: pool.DefaultHealthCheckInterval,
+			},
+		},
+		Action: executor.gateway,
+	}
+}
+
+// gateway executes when gateway subcommand is specified.
+func (executor *Executor) gateway(c *cli.Context) error {
+	if isDryRun(c) {
+		return fmt.Errorf("gateway: %w", ErrDryRunNotSupported)
+	}
+
+	if c.String("tokens") == "" {
+		return ErrEmptyTokens
+	}
+
+	tokens, err := gateway.NewTokens(c.String("tokens"))
+	if err != nil {
+		return fmt.Errorf("tokens: %w", err)
+	}
+
+	sessions, err := executor.envSessions(c)
+	if err != nil {
+		return err
+	}
+
+	options := []pool.Option{pool.SetMaxConns(c.Int("max-conns")), pool.SetIdleTimeout(c.Duration("idle-timeout"))}
+	if command := c.String("health-check"); command != "" {
+		options = append(options, pool.SetHealthCheck(func(client rconcli.Client) error {
+			_, err := client.Execute(command)
+
+			return err
+		}, c.Duration("health-check-interval")))
+	}
+
+	gw := gateway.New(sessions, tokens, func(ses *rconcli.Session) (rconcli.Client, error) {
+		return executor.dialClient(c.Context, ses)
+	}, options...)
+	defer gw.Close()
+
+	server := &http.Server{Addr: c.String("listen"), Handler: gw, ReadHeaderTimeout: rconcli.DefaultTimeout}
+
+	ctx := c.Context
+
+	go func() {
+		<-ctx.Done()
+
+		_ = server.Close()
+	}()
+
+	_, _ = fmt.Fprintf(executor.w, "Gateway is listening on %s (press ^C to stop)\n", server.Addr)
+
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("gateway: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+var (
+	// ErrUnknownRole is returned when role is not defined in the config
+	// environment.
+	ErrUnknownRole = errors.New("unknown role: to use roles define them in the config environment")
+
+	// ErrNotConfirmed is returned when command requiring confirmation was
+	// not confirmed.
+	ErrNotConfirmed = errors.New("command is not confirmed: to confirm without prompt add --yes")
+)
+
+// guard refuses commands forbidden for the role or denied in the environment
+// and asks confirmation of dangerous ones. Refused commands are written to
+// the audit log.
+func (executor *Executor) guard(ctx context.Context, w io.Writer, ses *rconcli.Session, command string) error {
+	if refused := ses.Refuse(ses.Role, command); refused != nil {
+		if err := rconcli.WriteAudit(ses.ResolveAuditLog(), ses.Address, ses.Role, command, refused.Error()); err != nil {
+			_, _ = fmt.Fprintln(w, fmt.Errorf("log: %w", err))
+		}
+
+		return &ExitError{Code: ExitCodeDenied, Err: fmt.Errorf("execute: %w: %q", refused, command)}
+	}
+
+	if ses.AssumeYes || !ses.NeedsConfirm(command) {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "Execute %q on %s? [y/N]: ", command, ses.Address)
+
+	answer, err := executor.readLine(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintln(w)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return &ExitError{Code: ExitCodeDenied, Err: fmt.Errorf("execute: %w: %q", ErrNotConfirmed, command)}
+	}
+}
+
+// checkRole returns error if the session role is not defined in the config
+// environment.
+func checkRole(ses *rconcli.Session) error {
+	if ses.Role == "" {
+		return nil
+	}
+
+	if _, ok := ses.Roles[ses.Role]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownRole, ses.Role)
+	}
+
+	return nil
+}
+
+// readLine returns the next line of the input. The input is shared with
+// interactive mode to read confirmations from the same stream as commands.
+func (executor *Executor) readLine(ctx context.Context) (string, error) {
+	if executor.lines == nil {
+		if executor.r == nil {
+			return "", io.EOF
+		}
+
+		executor.lines = scanLines(executor.r, executor.done)
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case line, ok := <-executor.lines:
+		if !ok {
+			return "", io.EOF
+		}
+
+		return line, nil
+	}
+}
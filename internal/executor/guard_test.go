@@ -0,0 +1,153 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	configFileName := "rcon-guard-test-local.yaml"
+	auditFileName := "rcon-guard-test-audit.log"
+	createFile(configFileName, "prod:\n  address: \""+serverRCON.Addr()+"\"\n  password: \"password\"\n"+
+		"  protected: true\n  confirm: [\"^help\"]\n  deny: [\"^wipe\"]\n  audit_log: \""+auditFileName+"\"\n"+
+		"  roles:\n    moderator:\n      allow: [\"^(kick|ban|say) \"]\n")
+	defer os.Remove(configFileName)
+	defer os.Remove(auditFileName)
+
+	run := func(r string, args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(strings.NewReader(r), w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-c=" + configFileName, "-e=prod"}, args...))
+
+		return w.String(), err
+	}
+
+	// Test denied command is refused with the distinct exit code.
+	t.Run("denied", func(t *testing.T) {
+		out, err := run("", "--yes", "wipe")
+		assert.ErrorIs(t, err, rconcli.ErrCommandDenied)
+		assert.Equal(t, executor.ExitCodeDenied, executor.ExitCode(err))
+		assert.Empty(t, out)
+	})
+
+	// Test denied probe is refused like other commands.
+	t.Run("denied probe", func(t *testing.T) {
+		_, err := run("", "wait", "--probe=wipe")
+		assert.ErrorIs(t, err, rconcli.ErrCommandDenied)
+		assert.Equal(t, executor.ExitCodeDenied, executor.ExitCode(err))
+
+		out, err := run("", "ping", "--probe=wipe")
+		assert.Equal(t, executor.PingUnknown, executor.ExitCode(err))
+		assert.Contains(t, out, rconcli.ErrCommandDenied.Error())
+	})
+
+	// Test command is refused without confirmation in scripts.
+	t.Run("not confirmed", func(t *testing.T) {
+		out, err := run("", "quit")
+		assert.ErrorIs(t, err, executor.ErrNotConfirmed)
+		assert.Equal(t, executor.ExitCodeDenied, executor.ExitCode(err))
+		assert.Contains(t, out, `Execute "quit" on `+serverRCON.Addr()+"? [y/N]: ")
+	})
+
+	// Test confirmed command is executed.
+	t.Run("confirmed", func(t *testing.T) {
+		out, err := run("y\n", "help")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "Can I help you?")
+	})
+
+	// Test --yes skips confirmation prompt.
+	t.Run("yes", func(t *testing.T) {
+		out, err := run("", "--yes", "help")
+		assert.NoError(t, err)
+		assert.Equal(t, "Can I help you?\n", out)
+	})
+
+	// Test refused commands do not break interactive mode.
+	t.Run("interactive", func(t *testing.T) {
+		out, err := run("wipe\nhelp\nn\nhelp\ny\n:q\n")
+		assert.NoError(t, err)
+		assert.Contains(t, out, rconcli.ErrCommandDenied.Error())
+		assert.Contains(t, out, executor.ErrNotConfirmed.Error())
+		assert.Equal(t, 1, strings.Count(out, "Can I help you?"))
+	})
+}
+
+func TestRole(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	configFileName := "rcon-role-test-local.yaml"
+	auditFileName := "rcon-role-test-audit.log"
+	createFile(configFileName, "prod:\n  address: \""+serverRCON.Addr()+"\"\n  password: \"password\"\n"+
+		"  audit_log: \""+auditFileName+"\"\n  roles:\n    moderator:\n      allow: [\"^(kick|ban|say) \"]\n")
+	defer os.Remove(configFileName)
+	defer os.Remove(auditFileName)
+
+	run := func(args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-c=" + configFileName, "-e=prod"}, args...))
+
+		return w.String(), err
+	}
+
+	// Test unknown role is rejected.
+	t.Run("unknown role", func(t *testing.T) {
+		_, err := run("--role=admin", "help")
+		assert.ErrorIs(t, err, executor.ErrUnknownRole)
+	})
+
+	// Test allowed command is executed.
+	t.Run("allowed", func(t *testing.T) {
+		out, err := run("--role=moderator", "say hello")
+		assert.NoError(t, err)
+		assert.Equal(t, "unknown command\n", out)
+	})
+
+	// Test forbidden probe is refused like other commands.
+	t.Run("forbidden probe", func(t *testing.T) {
+		_, err := run("--role=moderator", "wait", "--probe=help")
+		assert.ErrorIs(t, err, rconcli.ErrRoleForbidden)
+		assert.Equal(t, executor.ExitCodeDenied, executor.ExitCode(err))
+
+		out, err := run("--role=moderator", "ping", "--probe=help")
+		assert.Equal(t, executor.PingUnknown, executor.ExitCode(err))
+		assert.Contains(t, out, rconcli.ErrRoleForbidden.Error())
+	})
+
+	// Test forbidden command is refused and audited.
+	t.Run("forbidden", func(t *testing.T) {
+		out, err := run("--role=moderator", "help")
+		assert.ErrorIs(t, err, rconcli.ErrRoleForbidden)
+		assert.Equal(t, executor.ExitCodeDenied, executor.ExitCode(err))
+		assert.Empty(t, out)
+
+		data, err := os.ReadFile(auditFileName)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "AUDIT "+serverRCON.Addr()+" role=moderator: help\n"+rconcli.ErrRoleForbidden.Error())
+	})
+}
@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultMockAddress is the default address for mock server to listen on.
+const DefaultMockAddress = "127.0.0.1:16260"
+
+// serveMockCommand returns the subcommand starting local mock server.
+func (executor *Executor) serveMockCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve-mock",
+		Usage: "Start local mock server replying with canned responses",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "Set host and port to listen on",
+				Value: DefaultMockAddress,
+			},
+			&cli.StringFlag{
+				Name:  "password",
+				Usage: "Set password to authenticate clients",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Specify type of mock server",
+				Value: rconcli.DefaultProtocol,
+			},
+			&cli.StringFlag{
+				Name:  "responses",
+				Usage: "Path to the file with canned responses. If not specified all commands are unknown",
+			},
+		},
+		Action: executor.serveMock,
+	}
+}
+
+// serveMock executes when serve-mock subcommand is specified.
+func (executor *Executor) serveMock(c *cli.Context) error {
+	if isDryRun(c) {
+		return fmt.Errorf("mock: %w", ErrDryRunNotSupported)
+	}
+
+	responses, err := mock.NewResponses(c.String("responses"))
+	if err != nil {
+		return fmt.Errorf("responses: %w", err)
+	}
+
+	server, err := mock.NewServer(c.String("type"), c.String("listen"), c.String("password"), responses)
+	if err != nil {
+		return fmt.Errorf("mock: %w", err)
+	}
+	defer server.Close()
+
+	_, _ = fmt.Fprintf(executor.w, "Mock %s server is listening on %s (press ^C to stop)\n", c.String("type"), server.Addr())
+
+	<-c.Context.Done()
+
+	return nil
+}
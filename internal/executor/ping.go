@@ -0,0 +1,379 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// Nagios compatible exit codes of ping command.
+const (
+	PingOK       = 0
+	PingWarning  = 1
+	PingCritical = 2
+	PingUnknown  = 3
+)
+
+var (
+	// ErrProbeMismatch is returned when probe command response does not match
+	// the expected regular expression.
+	ErrProbeMismatch = errors.New("response does not match")
+
+	// ErrInvalidThresholds is returned when warning latency threshold is not
+	// below critical one.
+	ErrInvalidThresholds = errors.New("warning threshold must be below critical threshold")
+)
+
+// pingStatuses contains status names for Nagios exit codes.
+var pingStatuses = map[int]string{
+	PingOK:       "OK",
+	PingWarning:  "WARNING",
+	PingCritical: "CRITICAL",
+	PingUnknown:  "UNKNOWN",
+}
+
+// pingSeverity orders statuses to choose the worst one.
+var pingSeverity = map[int]int{PingOK: 0, PingUnknown: 1, PingWarning: 2, PingCritical: 3}
+
+// pingResult contains health check result of the environment.
+type pingResult struct {
+	env    string
+	status int
+	// dial is the tcp connect latency of the check connection including
+	// opening of the session tunnel.
+	dial time.Duration
+	// login is the dial and authentication latency of the protocol
+	// connection executing the probe command.
+	login   time.Duration
+	command time.Duration
+	err     error
+}
+
+// total returns the latency of the protocol connection. The check
+// connection is not included, because it is opened separately.
+func (result *pingResult) total() time.Duration {
+	return result.login + result.command
+}
+
+// pingCommand returns the subcommand checking remote servers health.
+func (executor *Executor) pingCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ping",
+		Usage: "Check that remote servers accept connections and exit with Nagios compatible code",
+		Description: "Dials and authenticates to each environment passed as arguments or to the environment from " +
+			"--env flag. Exits with 0 (OK), 1 (WARNING), 2 (CRITICAL) or 3 (UNKNOWN) code.",
+		ArgsUsage: "[environments...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Check all environments from the configuration file",
+			},
+			&cli.StringFlag{
+				Name:  "probe",
+				Usage: "Command to execute after successful authentication",
+			},
+			&cli.StringFlag{
+				Name:  "expect",
+				Usage: "Regular expression the probe command response must match",
+			},
+			&cli.DurationFlag{
+				Name:  "warning",
+				Usage: "Total latency to report WARNING status",
+			},
+			&cli.DurationFlag{
+				Name:  "critical",
+				Usage: "Total latency to report CRITICAL status",
+			},
+		},
+		Action:       executor.ping,
+		OnUsageError: pingUsageError,
+	}
+}
+
+// pingUsageError returns flag parsing errors with UNKNOWN exit code, so
+// monitoring does not report misconfigured checks as CRITICAL.
+func pingUsageError(_ *cli.Context, err error, _ bool) error {
+	return &ExitError{Code: PingUnknown, Err: err}
+}
+
+// ping executes when ping subcommand is specified.
+func (executor *Executor) ping(c *cli.Context) error {
+	if warning, critical := c.Duration("warning"), c.Duration("critical"); warning > 0 && critical > 0 &&
+		warning >= critical {
+		return &ExitError{Code: PingUnknown, Err: fmt.Errorf("%w: %s >= %s", ErrInvalidThresholds, warning, critical)}
+	}
+
+	var expect *regexp.Regexp
+
+	if expr := c.String("expect"); expr != "" {
+		var err error
+		if expect, err = regexp.Compile(expr); err != nil {
+			return &ExitError{Code: PingUnknown, Err: fmt.Errorf("expect: %w", err)}
+		}
+	}
+
+	envs, err := executor.pingEnvs(c)
+	if err != nil {
+		return &ExitError{Code: PingUnknown, Err: err}
+	}
+
+	if isDryRun(c) {
+		return executor.pingDryRun(c, envs)
+	}
+
+	results := make([]pingResult, 0, len(envs))
+	status := PingOK
+
+	for _, env := range envs {
+		result := executor.pingEnv(c, env, expect)
+		if pingSeverity[result.status] > pingSeverity[status] {
+			status = result.status
+		}
+
+		results = append(results, result)
+	}
+
+	executor.printPing(status, results)
+
+	if status != PingOK {
+		return &ExitError{Code: status}
+	}
+
+	return nil
+}
+
+// pingDryRun prints plans of environments checks instead of dialing.
+func (executor *Executor) pingDryRun(c *cli.Context, envs []string) error {
+	var commands []string
+	if probe := c.String("probe"); probe != "" {
+		commands = []string{probe}
+	}
+
+	for _, env := range envs {
+		ses, err := executor.newSession(c, env)
+		if err != nil {
+			return &ExitError{Code: PingUnknown, Err: err}
+		}
+
+		plan, err := executor.newPlan(c, ses, commands)
+		if err != nil {
+			return &ExitError{Code: PingUnknown, Err: err}
+		}
+
+		if plan.Env != "" {
+			plan.Env = env
+		}
+
+		plan.Mode = "none, connection is checked"
+		plan.Print(executor.w)
+	}
+
+	return nil
+}
+
+// pingEnvs returns environments to check.
+func (executor *Executor) pingEnvs(c *cli.Context) ([]string, error) {
+	// Config environments are not used when both address and password are
+	// set with flags, so the address is checked once.
+	if c.IsSet("address") && c.IsSet("password") {
+		return []string{c.String("env")}, nil
+	}
+
+	if !c.Bool("all") {
+		if c.Args().Present() {
+			return c.Args().Slice(), nil
+		}
+
+		return []string{c.String("env")}, nil
+	}
+
+	return executor.configEnvs(c)
+}
+
+// pingEnv checks that the environment accepts tcp connections, dials and
+// authenticates to it and executes probe command. Protocol clients dial tcp
+// themselves, so tcp connect latency is measured on the check connection and
+// login latency includes tcp connect of the protocol connection.
+func (executor *Executor) pingEnv(c *cli.Context, env string, expect *regexp.Regexp) pingResult {
+	result := pingResult{env: env, status: PingUnknown}
+
+	ses, err := executor.newSession(c, env)
+	if err != nil {
+		result.err = err
+
+		return result
+	}
+
+	if !ses.HasAddress() {
+		result.err = ErrEmptyAddress
+
+		return result
+	}
+
+	if probe := c.String("probe"); probe != "" {
+		if err = executor.guard(c.Context, executor.w, ses, probe); err != nil {
+			result.err = err
+
+			return result
+		}
+	}
+
+	result.status = PingCritical
+
+	start := time.Now()
+
+	if ses, err = dialAddress(c.Context, ses); err != nil {
+		result.err = fmt.Errorf("dial: %w", err)
+
+		return result
+	}
+
+	result.dial = time.Since(start)
+
+	start = time.Now()
+
+	client, err := executor.dialClient(c.Context, ses)
+	if err != nil {
+		result.err = err
+
+		return result
+	}
+	defer client.Close()
+
+	result.login = time.Since(start)
+
+	if probe := c.String("probe"); probe != "" {
+		start = time.Now()
+
+		response, err := client.Execute(probe)
+		if err != nil {
+			result.err = fmt.Errorf("probe: %w", err)
+
+			return result
+		}
+
+		result.command = time.Since(start)
+
+		if expect != nil && !expect.MatchString(response) {
+			result.err = fmt.Errorf("probe: %w %q", ErrProbeMismatch, expect.String())
+
+			return result
+		}
+	}
+
+	result.status = pingThreshold(c, result.total())
+
+	return result
+}
+
+// pingThreshold returns status for the latency.
+func pingThreshold(c *cli.Context, latency time.Duration) int {
+	if critical := c.Duration("critical"); critical > 0 && latency >= critical {
+		return PingCritical
+	}
+
+	if warning := c.Duration("warning"); warning > 0 && latency >= warning {
+		return PingWarning
+	}
+
+	return PingOK
+}
+
+// printPing prints results in Nagios plugin output format: status line with
+// performance data followed by the environment details.
+func (executor *Executor) printPing(status int, results []pingResult) {
+	var ok int
+
+	perf := make([]string, 0, len(results))
+
+	for i := range results {
+		if results[i].status == PingOK {
+			ok++
+		}
+
+		if results[i].err == nil {
+			perf = append(perf,
+				pingPerf(results[i].env+"_dial", results[i].dial),
+				pingPerf(results[i].env+"_login", results[i].login),
+				pingPerf(results[i].env+"_command", results[i].command),
+			)
+		}
+	}
+
+	_, _ = fmt.Fprintf(executor.w, "RCON %s - %d of %d environments OK | %s\n",
+		pingStatuses[status], ok, len(results), strings.Join(perf, " "))
+
+	for i := range results {
+		if results[i].err != nil {
+			_, _ = fmt.Fprintf(executor.w, "%s: %s - %v\n", results[i].env, pingStatuses[results[i].status], results[i].err)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(executor.w, "%s: %s - dial %s, login %s, command %s\n", results[i].env,
+			pingStatuses[results[i].status], results[i].dial, results[i].login, results[i].command)
+	}
+}
+
+// pingPerf returns Nagios performance data of the latency in seconds.
+func pingPerf(label string, latency time.Duration) string {
+	return "'" + label + "'=" + strconv.FormatFloat(latency.Seconds(), 'f', 6, 64) + "s"
+}
+
+// dialAddress returns the session copy with the first of its addresses
+// accepting TCP connections. Addresses are connected through the tunnel of
+// the session, so servers reachable only through SOCKS5 proxy or SSH bastion
+// are not reported as unreachable.
+func dialAddress(ctx context.Context, ses *rconcli.Session) (*rconcli.Session, error) {
+	addresses, err := rconcli.Addresses(ctx, ses)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel, err := rconcli.OpenTunnel(ctx, ses)
+	if err != nil {
+		return nil, err
+	}
+	defer tunnel.Close()
+
+	errs := make([]error, 0, len(addresses))
+
+	for _, address := range addresses {
+		hostPort, err := rconcli.HostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := dialTimeout(ctx, tunnel, hostPort, ses.ResolveDialTimeout())
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		_ = conn.Close()
+
+		dialed := *ses
+		dialed.Address, dialed.Addresses, dialed.SRV = address, nil, ""
+
+		return &dialed, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// dialTimeout connects to the address through the tunnel within timeout.
+func dialTimeout(ctx context.Context, tunnel *rconcli.Tunnel, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return tunnel.DialContext(ctx, "tcp", address)
+}
@@ -0,0 +1,154 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/internal/transport/transporttest"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPing(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password", CommandResponseDelay: 50 * time.Millisecond}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	configFileName := "rcon-ping-test-local.yaml"
+	stringBody := fmt.Sprintf(ConfigLayoutYAML, "good", serverRCON.Addr(), "password", "", "") + "\n" +
+		fmt.Sprintf(ConfigLayoutYAML, "bad", serverRCON.Addr(), "wrong", "", "")
+	createFile(configFileName, stringBody)
+	defer os.Remove(configFileName)
+
+	// Test OK status with probe command.
+	t.Run("ok", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "ping", "--probe=help", "--expect=help"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "RCON OK - 1 of 1 environments OK | 'default_dial'=")
+	})
+
+	// Test WARNING status when latency exceeds the threshold.
+	t.Run("warning", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "ping", "--probe=help", "--warning=10ms"})
+		assert.Equal(t, executor.PingWarning, executor.ExitCode(err))
+		assert.Equal(t, "", err.Error())
+		assert.Contains(t, w.String(), "RCON WARNING")
+	})
+
+	// Test swapped thresholds are rejected with UNKNOWN status.
+	t.Run("invalid thresholds", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "ping",
+			"--warning=1s", "--critical=100ms"})
+		assert.ErrorIs(t, err, executor.ErrInvalidThresholds)
+		assert.Equal(t, executor.PingUnknown, executor.ExitCode(err))
+	})
+
+	// Test CRITICAL status when probe response does not match.
+	t.Run("probe mismatch", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "ping", "--probe=help", "--expect=^41.78"})
+		assert.Equal(t, executor.PingCritical, executor.ExitCode(err))
+		assert.Contains(t, w.String(), "default: CRITICAL - probe: response does not match")
+	})
+
+	// Test all environments from the config.
+	t.Run("all environments", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=" + configFileName, "ping", "--all"})
+		assert.Equal(t, executor.PingCritical, executor.ExitCode(err))
+		assert.Contains(t, w.String(), "RCON CRITICAL - 1 of 2 environments OK")
+		assert.Contains(t, w.String(), "bad: CRITICAL - auth: rcon: authentication failed")
+		assert.Contains(t, w.String(), "good: OK")
+	})
+
+	// Test the address set with flags is checked once.
+	t.Run("flags", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=" + configFileName, "-a=" + serverRCON.Addr(),
+			"-p=password", "ping", "--all"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "RCON OK - 1 of 1 environments OK | 'default_dial'=")
+		assert.Contains(t, w.String(), "'default_login'=")
+	})
+
+	// Test WebRCON URL address is dialed at its host.
+	t.Run("web url", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolWebRCON, "127.0.0.1:0", "password", &mock.Responses{})
+		assert.NoError(t, err)
+		defer server.Close()
+
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err = app.Run(context.Background(), []string{"", "-a=ws://" + server.Addr(), "-p=password", "-t=web",
+			"ping", "--probe=status"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "RCON OK - 1 of 1 environments OK")
+	})
+
+	// Test the check connection is opened through the proxy.
+	t.Run("proxy", func(t *testing.T) {
+		proxy, err := transporttest.NewSOCKS5("", "")
+		assert.NoError(t, err)
+		defer proxy.Close()
+
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err = app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--proxy=" + proxy.URL(),
+			"ping"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "RCON OK - 1 of 1 environments OK")
+		assert.Equal(t, 2, proxy.Connections())
+	})
+
+	// Test UNKNOWN status when address is not set.
+	t.Run("unknown", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=" + configFileName, "ping", "missing"})
+		assert.Equal(t, executor.PingUnknown, executor.ExitCode(err))
+		assert.Contains(t, w.String(), "missing: UNKNOWN - "+executor.ErrEmptyAddress.Error())
+	})
+}
@@ -0,0 +1,257 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// PasswordMask replaces the password in printed plan.
+const PasswordMask = "******"
+
+var (
+	// ErrInvalidPlan is returned when dry run found that commands cannot be
+	// executed.
+	ErrInvalidPlan = errors.New("invalid plan")
+
+	// ErrDryRunNotSupported is returned when dry run is requested for
+	// the command serving requests, which has no plan to print.
+	ErrDryRunNotSupported = errors.New("dry run is not supported by the command")
+)
+
+// Plan is the resolved execution plan of the run.
+type Plan struct {
+	Env      string
+	Session  *rconcli.Session
+	Commands []string
+	// Addresses are the configured addresses with the default port of
+	// the protocol applied. SRV record is not resolved in dry run.
+	Addresses []string
+	// Mode describes what is done without commands. Interactive mode is
+	// assumed if it is empty.
+	Mode string
+}
+
+// newPlan resolves the plan and validates the session without dialing.
+func (executor *Executor) newPlan(c *cli.Context, ses *rconcli.Session, commands []string) (*Plan, error) {
+	plan := &Plan{Env: c.String("env"), Session: ses, Commands: commands}
+
+	// Config environment is not used when both address and password
+	// are set with flags.
+	if c.IsSet("address") && c.IsSet("password") {
+		plan.Env = ""
+	}
+
+	switch ses.Type {
+	case "", rconcli.ProtocolRCON, rconcli.ProtocolTELNET, rconcli.ProtocolWebRCON:
+	default:
+		return plan, fmt.Errorf("%w: %w %q", ErrInvalidPlan, rconcli.ErrUnsupportedProtocol, ses.Type)
+	}
+
+	switch ses.ResolveResponseEnd() {
+	case rconcli.ResponseEndSingle, rconcli.ResponseEndSentinel, rconcli.ResponseEndMirror, rconcli.ResponseEndIdle:
+	default:
+		return plan, fmt.Errorf("%w: %w %q", ErrInvalidPlan, rconcli.ErrUnsupportedResponseEnd, ses.ResponseEnd)
+	}
+
+	if ses.Playback != "" {
+		return plan, nil
+	}
+
+	if !ses.HasAddress() {
+		return plan, fmt.Errorf("%w: %w", ErrInvalidPlan, ErrEmptyAddress)
+	}
+
+	// SRV record is not looked up, nothing is sent over the network.
+	static := *ses
+	static.SRV = ""
+
+	addresses, err := rconcli.Addresses(c.Context, &static)
+	if err != nil && !errors.Is(err, rconcli.ErrNoAddress) {
+		return plan, fmt.Errorf("%w: %w", ErrInvalidPlan, err)
+	}
+
+	plan.Addresses = addresses
+
+	if ses.Password == "" && len(plan.Commands) != 0 {
+		return plan, fmt.Errorf("%w: %w", ErrInvalidPlan, ErrEmptyPassword)
+	}
+
+	for _, command := range plan.Commands {
+		if command == "" {
+			return plan, fmt.Errorf("%w: %w", ErrInvalidPlan, ErrCommandEmpty)
+		}
+	}
+
+	return plan, nil
+}
+
+// Print writes the plan to w. Password is masked.
+func (plan *Plan) Print(w io.Writer) {
+	ses := plan.Session
+
+	env := plan.Env
+	if env == "" {
+		env = "(flags)"
+	}
+
+	protocol := ses.Type
+	if protocol == "" {
+		protocol = rconcli.DefaultProtocol
+	}
+
+	password := ""
+	if ses.Password != "" {
+		password = PasswordMask
+	}
+
+	_, _ = fmt.Fprint(w, "Dry run, nothing is sent to remote server.\n")
+	_, _ = fmt.Fprintf(w, "Environment:  %s\n", env)
+	address, fallbacks := ses.Address, ses.Addresses
+	if len(plan.Addresses) != 0 {
+		address, fallbacks = plan.Addresses[0], plan.Addresses[1:]
+	}
+
+	_, _ = fmt.Fprintf(w, "Address:      %s\n", address)
+	if len(fallbacks) != 0 {
+		_, _ = fmt.Fprintf(w, "Fallbacks:    %s\n", strings.Join(fallbacks, ", "))
+	}
+
+	if ses.SRV != "" {
+		_, _ = fmt.Fprintf(w, "SRV:          %s\n", ses.SRV)
+	}
+
+	_, _ = fmt.Fprintf(w, "Protocol:     %s\n", protocol)
+	_, _ = fmt.Fprintf(w, "Password:     %s\n", password)
+	_, _ = fmt.Fprintf(w, "Dial timeout: %s\n", ses.ResolveDialTimeout())
+
+	if end := ses.ResolveResponseEnd(); protocol == rconcli.ProtocolRCON && end != rconcli.ResponseEndSingle {
+		_, _ = fmt.Fprintf(w, "Response end: %s\n", end)
+	}
+
+	if ses.Proxy != "" {
+		_, _ = fmt.Fprintf(w, "Proxy:        %s\n", maskURL(ses.Proxy))
+	}
+
+	if ses.Via != "" {
+		_, _ = fmt.Fprintf(w, "Via:          %s\n", maskURL(ses.Via))
+	}
+
+	if ses.TLSEnabled() {
+		_, _ = fmt.Fprintf(w, "TLS:          %s\n", tlsNotes(ses.TLS))
+	}
+
+	if ses.Log != "" {
+		_, _ = fmt.Fprintf(w, "Log:          %s\n", ses.Log)
+	}
+
+	if ses.Role != "" {
+		_, _ = fmt.Fprintf(w, "Role:         %s\n", ses.Role)
+	}
+
+	if ses.Record != "" {
+		_, _ = fmt.Fprintf(w, "Record:       %s\n", ses.Record)
+	}
+
+	if ses.Playback != "" {
+		_, _ = fmt.Fprintf(w, "Playback:     %s\n", ses.Playback)
+	}
+
+	if len(plan.Commands) == 0 {
+		mode := plan.Mode
+		if mode == "" {
+			mode = "read from input stream in interactive mode"
+		}
+
+		_, _ = fmt.Fprintf(w, "Commands:     %s\n", mode)
+
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "Commands (%d):\n", len(plan.Commands))
+
+	for i, command := range plan.Commands {
+		notes := "timeout " + ses.ResolveCommandTimeout(command).String()
+
+		switch {
+		case !ses.RoleAllows(ses.Role, command):
+			notes += ", not allowed for role " + ses.Role
+		case ses.IsDenied(command):
+			notes += ", denied"
+		case ses.NeedsConfirm(command) && !ses.AssumeYes:
+			notes += ", requires confirmation"
+		}
+
+		_, _ = fmt.Fprintf(w, "  %d. %s (%s)\n", i+1, command, notes)
+	}
+}
+
+// maskURL masks the password of the URL. Invalid URL is masked entirely,
+// because it may contain the password.
+func maskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return PasswordMask
+	}
+
+	return u.Redacted()
+}
+
+// tlsNotes describes TLS options of the plan.
+func tlsNotes(opts *rconcli.TLS) string {
+	if opts == nil {
+		return "enabled"
+	}
+
+	notes := "enabled"
+
+	if opts.CAFile != "" {
+		notes += ", ca " + opts.CAFile
+	}
+
+	if opts.CertFile != "" {
+		notes += ", client certificate " + opts.CertFile
+	}
+
+	if opts.ServerName != "" {
+		notes += ", server name " + opts.ServerName
+	}
+
+	if opts.InsecureSkipVerify {
+		notes += ", insecure"
+	}
+
+	return notes
+}
+
+// dryRun prints the execution plan instead of executing commands. Mode
+// describes what is done if there are no commands.
+func (executor *Executor) dryRun(c *cli.Context, ses *rconcli.Session, commands []string, mode string) error {
+	plan, err := executor.newPlan(c, ses, commands)
+	if err != nil {
+		return err
+	}
+
+	plan.Mode = mode
+
+	plan.Print(executor.w)
+
+	return nil
+}
+
+// isDryRun returns true if dry run is requested with the global flag or
+// the flag of the subcommand.
+func isDryRun(c *cli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("dry-run") {
+			return true
+		}
+	}
+
+	return false
+}
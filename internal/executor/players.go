@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon-cli/internal/table"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// ErrEmptyGame is returned when game specific command is called without
+// game profile.
+var ErrEmptyGame = errors.New("game is not set: to set game profile add --game or game to the config environment")
+
+// playersCommand returns the subcommand printing parsed player list.
+func (executor *Executor) playersCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "players",
+		Usage: "Print connected players parsed from the player list of the game",
+		Description: "Player list command and its parser are taken from the game profile set by --game flag or " +
+			"game option of the config environment. Without game profile and flags players command is sent " +
+			"to remote server as is.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Set output format of players: table, json, csv or tsv. Defaults to global output if it is not text",
+			},
+			&cli.StringFlag{
+				Name:  "command",
+				Usage: "Override player list command of the game",
+			},
+		},
+		Action: executor.players,
+	}
+}
+
+// players executes when players subcommand is specified.
+func (executor *Executor) players(c *cli.Context) error {
+	ses, err := executor.NewSession(c)
+	if err != nil {
+		return err
+	}
+
+	// Without game profile players is the command of the remote server,
+	// e.g. in Project Zomboid, as it was before the subcommand was added.
+	if ses.Game == "" && !c.IsSet("output") && !c.IsSet("command") {
+		return executor.run(c, ses, append([]string{c.Command.Name}, c.Args().Slice()...))
+	}
+
+	output := c.String("output")
+	if output == "" {
+		output = OutputTable
+		if executor.output != OutputText {
+			output = executor.output
+		}
+	}
+
+	if output != OutputJSON && !isTableOutput(output) {
+		return fmt.Errorf("%w %q", ErrUnsupportedOutput, output)
+	}
+
+	if ses.Game == "" {
+		return ErrEmptyGame
+	}
+
+	profile, err := game.Lookup(ses.Game)
+	if err != nil {
+		return err
+	}
+
+	if c.IsSet("command") {
+		profile.Players = c.String("command")
+	}
+
+	if isDryRun(c) {
+		_, _ = fmt.Fprint(executor.w, "Dry run, nothing is sent to remote server.\n")
+		_, _ = fmt.Fprintf(executor.w, "List players of %s (game %s): %s\n", ses.Address, profile.Name, profile.Players)
+
+		return nil
+	}
+
+	if ses.Playback == "" {
+		if !ses.HasAddress() {
+			return ErrEmptyAddress
+		}
+
+		if ses.Password == "" {
+			return ErrEmptyPassword
+		}
+	}
+
+	if err = executor.guard(c.Context, executor.w, ses, profile.Players); err != nil {
+		return err
+	}
+
+	if err = executor.Dial(c.Context, ses); err != nil {
+		return fmt.Errorf("players: %w", err)
+	}
+
+	response, err := rconcli.Execute(c.Context, executor.client, profile.Players)
+	if err != nil {
+		return &ExitError{Code: classify(err, ExitCodeCommand), Err: fmt.Errorf("players: %w", err)}
+	}
+
+	if err = rconcli.WriteLog(ses.Log, ses.Address, profile.Players, response); err != nil {
+		_, _ = fmt.Fprintln(executor.w, fmt.Errorf("log: %w", err))
+	}
+
+	players, err := profile.ParsePlayers(response)
+	if err != nil {
+		return &ExitError{Code: ExitCodeCommand, Err: fmt.Errorf("players: %w", err)}
+	}
+
+	if output == OutputJSON {
+		data, _ := json.Marshal(players)
+		_, _ = fmt.Fprintln(executor.w, string(data))
+
+		return nil
+	}
+
+	if err = executor.table.render(executor.w, output, playersTable(players)); err != nil {
+		return fmt.Errorf("players: %w", err)
+	}
+
+	return nil
+}
+
+// playersTable returns players as table with total in the footer. Fields
+// not provided by the game are empty.
+func playersTable(players []game.Player) *table.Table {
+	tbl := &table.Table{
+		Header: []string{"name", "id", "ping", "ip", "connected"},
+		Rows:   make([][]string, 0, len(players)),
+		Footer: []string{fmt.Sprintf("Total: %d", len(players))},
+	}
+
+	for _, player := range players {
+		ping, connected := "", ""
+
+		if player.Ping != 0 {
+			ping = strconv.Itoa(player.Ping)
+		}
+
+		if player.Connected != 0 {
+			connected = player.Connected.Round(time.Second).String()
+		}
+
+		tbl.Rows = append(tbl.Rows, []string{player.Name, player.ID, ping, player.IP, connected})
+	}
+
+	return tbl
+}
@@ -0,0 +1,95 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayers(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch c.Request().Body() {
+			case "players":
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "Players connected (2):\n-admin\n-bob\n").WriteTo(c.Conn())
+			case "list":
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "Unknown command").WriteTo(c.Conn())
+			default:
+				handlersRCON(c)
+			}
+		}),
+	)
+	defer serverRCON.Close()
+
+	run := func(args ...string) (string, error) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), append([]string{"", "-a=" + serverRCON.Addr(), "-p=password"}, args...))
+
+		return w.String(), err
+	}
+
+	t.Run("table", func(t *testing.T) {
+		out, err := run("--game=zomboid", "players")
+		assert.NoError(t, err)
+		assert.Equal(t, "name   id  ping  ip  connected\nadmin\nbob\nTotal: 2\n", out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--game=zomboid", "players", "--output=json"},
+			{"--game=zomboid", "--output=json", "players"},
+		} {
+			out, err := run(args...)
+			assert.NoError(t, err, args)
+			assert.JSONEq(t, `[{"name":"admin","ping":0},{"name":"bob","ping":0}]`, out, args)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		out, err := run("--game=zomboid", "players", "--output=csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "name,id,ping,ip,connected\nadmin,,,,\nbob,,,,\n", out)
+	})
+
+	t.Run("command override", func(t *testing.T) {
+		out, err := run("--game=rust", "--dry-run", "players", "--command=playerlist")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "(game rust): playerlist")
+	})
+
+	// Test players command is sent as is without game profile.
+	t.Run("raw command", func(t *testing.T) {
+		out, err := run("players", "version")
+		assert.NoError(t, err)
+		assert.Equal(t, "Players connected (2):\n-admin\n-bob\n--------\nunknown command\n", out)
+	})
+
+	t.Run("no game", func(t *testing.T) {
+		_, err := run("players", "--output=json")
+		assert.ErrorIs(t, err, executor.ErrEmptyGame)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+
+	t.Run("unsupported output", func(t *testing.T) {
+		_, err := run("--game=zomboid", "players", "--output=xml")
+		assert.ErrorIs(t, err, executor.ErrUnsupportedOutput)
+	})
+
+	// Test error response is reported instead of empty list.
+	t.Run("unexpected response", func(t *testing.T) {
+		_, err := run("--game=minecraft", "players")
+		assert.ErrorIs(t, err, game.ErrUnexpectedResponse)
+		assert.Equal(t, executor.ExitCodeCommand, executor.ExitCode(err))
+	})
+}
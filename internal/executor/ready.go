@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+// ErrNotReady is returned when remote server does not answer until timeout.
+var ErrNotReady = errors.New("server is not ready")
+
+// waitReady dials remote server and executes the probe command every interval
+// until both succeed or timeout is exceeded. Zero timeout means no timeout.
+// Authentication errors are returned immediately if failFast is true,
+// otherwise they are retried, because starting servers can refuse
+// authentication until they are ready. Progress is written to w.
+func (executor *Executor) waitReady(
+	ctx context.Context, w io.Writer, ses *rconcli.Session, probe string, timeout, interval time.Duration, failFast bool,
+) error {
+	parent := ctx
+
+	// Probe is refused once, so it is confirmed and audited once.
+	if probe != "" {
+		if err := executor.guard(ctx, w, ses, probe); err != nil {
+			return err
+		}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := executor.probe(ctx, ses, probe)
+		if err == nil {
+			_, _ = fmt.Fprintf(w, "Server %s is ready after %s (attempt %d)\n",
+				ses.Address, time.Since(start).Round(time.Millisecond), attempt)
+
+			return nil
+		}
+
+		executor.reset()
+
+		// Wrong password is not fixed by waiting.
+		if failFast && classify(err, ExitCodeFailure) == ExitCodeAuth {
+			return fmt.Errorf("wait: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(w, "Server %s is not ready (attempt %d): %v\n", ses.Address, attempt, err)
+
+		if err = sleep(ctx, interval); err == nil {
+			continue
+		}
+
+		if parent.Err() != nil {
+			return fmt.Errorf("wait: %w", parent.Err())
+		}
+
+		return &ExitError{Code: ExitCodeTimeout, Err: fmt.Errorf("wait: %w after %s", ErrNotReady, timeout)}
+	}
+}
+
+// probe dials remote server and executes the probe command if it is set.
+func (executor *Executor) probe(ctx context.Context, ses *rconcli.Session, probe string) error {
+	if err := executor.Dial(ctx, ses); err != nil {
+		return err
+	}
+
+	if probe == "" {
+		return nil
+	}
+
+	if _, err := rconcli.Execute(ctx, executor.client, probe); err != nil {
+		return fmt.Errorf("probe: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// ErrEmptyReplayFile is returned when replay command is called without
+// log file name.
+var ErrEmptyReplayFile = errors.New("log file is not set: to set log file add replay path/to/file.log")
+
+// replayCommand returns the subcommand re-executing commands from log file.
+func (executor *Executor) replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "replay",
+		Usage:     "Re-execute commands from the log file on a remote server",
+		ArgsUsage: "<logfile>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print commands to replay and exit without executing them",
+			},
+			&cli.TimestampFlag{
+				Name:     "since",
+				Usage:    "Replay commands logged at or after the time. Example \"2006-01-02 15:04:05\"",
+				Layout:   rconcli.DefaultTimeLayout,
+				Timezone: time.Local,
+			},
+			&cli.TimestampFlag{
+				Name:     "until",
+				Usage:    "Replay commands logged at or before the time. Example \"2006-01-02 15:04:05\"",
+				Layout:   rconcli.DefaultTimeLayout,
+				Timezone: time.Local,
+			},
+			&cli.StringFlag{
+				Name:  "match",
+				Usage: "Replay only commands matching the regular expression",
+			},
+			&cli.BoolFlag{
+				Name:  "keep-delays",
+				Usage: "Wait between commands as long as it was waited in the log",
+			},
+			&cli.DurationFlag{
+				Name:  "max-delay",
+				Usage: "Compress kept delays between commands to the duration",
+			},
+		},
+		Action: executor.replay,
+	}
+}
+
+// replay executes when replay subcommand is specified.
+func (executor *Executor) replay(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return ErrEmptyReplayFile
+	}
+
+	records, err := rconcli.ReadLog(name)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+
+	if records, err = filterRecords(c, records); err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+
+	ses, err := executor.NewSession(c)
+	if err != nil {
+		return err
+	}
+
+	if isDryRun(c) {
+		_, _ = fmt.Fprintf(executor.w, "Replay %d commands on %s:\n", len(records), ses.Address)
+
+		for _, record := range records {
+			_, _ = fmt.Fprintf(executor.w, "[%s] %s\n", record.Time.Format(rconcli.DefaultTimeLayout), record.Request)
+		}
+
+		return nil
+	}
+
+	if len(records) == 0 {
+		return ErrCommandEmpty
+	}
+
+	if !ses.HasAddress() {
+		return ErrEmptyAddress
+	}
+
+	if ses.Password == "" {
+		return ErrEmptyPassword
+	}
+
+	if err = executor.Dial(c.Context, ses); err != nil {
+		return fmt.Errorf("execute: %w", err)
+	}
+
+	skipped := executor.skipped
+
+	for i, record := range records {
+		if i != 0 {
+			if err = sleep(c.Context, replayDelay(c, records[i-1], record)); err != nil {
+				return fmt.Errorf("execute: %w", err)
+			}
+		}
+
+		if err = executor.execute(c.Context, executor.w, ses, record.Request); err != nil {
+			return err
+		}
+
+		if i+1 != len(records) {
+			_, _ = fmt.Fprintln(executor.w, CommandsResponseSeparator)
+		}
+	}
+
+	return partialFailure(executor.skipped-skipped, len(records))
+}
+
+// filterRecords returns records matching time range and regular expression
+// from replay flags.
+func filterRecords(c *cli.Context, records []rconcli.LogRecord) ([]rconcli.LogRecord, error) {
+	var match *regexp.Regexp
+
+	if expr := c.String("match"); expr != "" {
+		var err error
+		if match, err = regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("match: %w", err)
+		}
+	}
+
+	since, until := c.Timestamp("since"), c.Timestamp("until")
+	filtered := make([]rconcli.LogRecord, 0, len(records))
+
+	for _, record := range records {
+		if since != nil && record.Time.Before(*since) {
+			continue
+		}
+
+		if until != nil && record.Time.After(*until) {
+			continue
+		}
+
+		if match != nil && !match.MatchString(record.Request) {
+			continue
+		}
+
+		filtered = append(filtered, record)
+	}
+
+	return filtered, nil
+}
+
+// replayDelay returns the duration to wait before executing the next record.
+func replayDelay(c *cli.Context, prev rconcli.LogRecord, next rconcli.LogRecord) time.Duration {
+	if !c.Bool("keep-delays") {
+		return 0
+	}
+
+	delay := next.Time.Sub(prev.Time)
+	if delay < 0 {
+		return 0
+	}
+
+	if limit := c.Duration("max-delay"); limit > 0 && delay > limit {
+		return limit
+	}
+
+	return delay
+}
+
+// sleep pauses for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
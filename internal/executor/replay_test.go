@@ -0,0 +1,66 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	logFileName := "rcon-replay-test.log"
+	defer os.Remove(logFileName)
+
+	for _, command := range []string{"help", "players", "help"} {
+		assert.NoError(t, rconcli.WriteLog(logFileName, "127.0.0.1:16260", command, "response"))
+	}
+
+	// Test empty log file name.
+	t.Run("empty log file", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "replay"})
+		assert.EqualError(t, err, "cli: "+executor.ErrEmptyReplayFile.Error())
+	})
+
+	// Test print commands without executing.
+	t.Run("dry run", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "replay", "--dry-run", "--match=^help$", logFileName})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "Replay 2 commands on "+serverRCON.Addr())
+		assert.NotContains(t, w.String(), "players")
+		assert.NotContains(t, w.String(), "Can I help you?")
+	})
+
+	// Positive test replay logged commands.
+	t.Run("no error", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "replay", "--keep-delays", "--max-delay=10ms", logFileName})
+		assert.NoError(t, err)
+		assert.Equal(t, "Can I help you?\n"+executor.CommandsResponseSeparator+"\nunknown command\n"+
+			executor.CommandsResponseSeparator+"\nCan I help you?\n", w.String())
+	})
+}
@@ -0,0 +1,324 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/urfave/cli/v2"
+)
+
+// Restart defaults.
+const (
+	// DefaultRestartMessage is the countdown warning sent to players.
+	DefaultRestartMessage = "Server restart in " + LeftPlaceholder
+
+	// DefaultWarnEvery is the default interval of countdown warnings.
+	DefaultWarnEvery = 5 * time.Minute
+
+	// DefaultReadyTimeout is the default time to wait for remote server
+	// to answer after shutdown.
+	DefaultReadyTimeout = 5 * time.Minute
+
+	// DefaultReadyInterval is the default interval between readiness checks.
+	DefaultReadyInterval = 5 * time.Second
+
+	// DefaultDownTimeout limits waiting for remote server to stop answering
+	// after shutdown command.
+	DefaultDownTimeout = time.Minute
+)
+
+// LeftPlaceholder is replaced with the time left to restart in countdown
+// message.
+const LeftPlaceholder = "{left}"
+
+// ErrEmptyShutdown is returned when restart command is called without game
+// profile and shutdown command.
+var ErrEmptyShutdown = errors.New("shutdown command is not set: to set game profile add --game or game to the config environment")
+
+// restartStep is the command executed at the offset from the restart start.
+type restartStep struct {
+	at      time.Duration
+	command string
+	// shutdown step may lose the connection.
+	shutdown bool
+}
+
+// restartCommand returns the subcommand restarting remote server with
+// countdown warnings.
+func (executor *Executor) restartCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "restart",
+		Usage: "Warn players, save and shut down remote server, then wait until it answers again",
+		Description: "Broadcast, save, shutdown and probe commands are taken from the game profile set by --game flag or " +
+			"game option of the config environment and can be overridden by flags.",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "in",
+				Usage: "Delay before shutdown, players are warned during the delay",
+			},
+			&cli.DurationFlag{
+				Name:  "warn-every",
+				Usage: "Interval of countdown warnings",
+				Value: DefaultWarnEvery,
+			},
+			&cli.StringFlag{
+				Name:  "message",
+				Usage: "Countdown warning, " + LeftPlaceholder + " is replaced with the time left",
+				Value: DefaultRestartMessage,
+			},
+			&cli.StringFlag{
+				Name:  "broadcast",
+				Usage: "Override broadcast command of the game, " + game.MessagePlaceholder + " is replaced with the message",
+			},
+			&cli.StringFlag{
+				Name:  "save",
+				Usage: "Override save command of the game",
+			},
+			&cli.StringFlag{
+				Name:  "shutdown",
+				Usage: "Override shutdown command of the game",
+			},
+			&cli.StringFlag{
+				Name:  "probe",
+				Usage: "Override command checking the server is ready after restart",
+			},
+			&cli.DurationFlag{
+				Name:  "ready-timeout",
+				Usage: "Time to wait for the server to answer after shutdown. Zero does not wait",
+				Value: DefaultReadyTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  "ready-interval",
+				Usage: "Interval between readiness checks",
+				Value: DefaultReadyInterval,
+			},
+		},
+		Action: executor.restart,
+	}
+}
+
+// restart executes when restart subcommand is specified.
+func (executor *Executor) restart(c *cli.Context) error {
+	ses, err := executor.NewSession(c)
+	if err != nil {
+		return err
+	}
+
+	profile, err := restartProfile(c, ses)
+	if err != nil {
+		return err
+	}
+
+	steps := restartSteps(c, &profile)
+
+	if isDryRun(c) {
+		printRestart(executor.w, ses, &profile, steps, c.Duration("ready-timeout"))
+
+		return nil
+	}
+
+	if ses.Playback == "" {
+		if !ses.HasAddress() {
+			return ErrEmptyAddress
+		}
+
+		if ses.Password == "" {
+			return ErrEmptyPassword
+		}
+	}
+
+	// Commands are confirmed before the countdown not to ask in the middle
+	// of it.
+	for _, step := range steps {
+		if err = executor.guard(c.Context, executor.w, ses, step.command); err != nil {
+			return err
+		}
+	}
+
+	ses.AssumeYes = true
+
+	if err = executor.Dial(c.Context, ses); err != nil {
+		return fmt.Errorf("restart: %w", err)
+	}
+
+	start := time.Now()
+
+	for _, step := range steps {
+		if err = sleep(c.Context, time.Until(start.Add(step.at))); err != nil {
+			return fmt.Errorf("restart: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(executor.w, "T-%s: %s\n", c.Duration("in")-step.at, step.command)
+
+		err = executor.execute(c.Context, executor.w, ses, step.command)
+
+		// Connection can be dropped during the long countdown, so warnings
+		// and save are retried once on a new connection. Timed out commands
+		// are not retried, because the server may have executed them.
+		if !step.shutdown && err != nil && classify(err, ExitCodeFailure) == ExitCodeNetwork {
+			_, _ = fmt.Fprintf(executor.w, "Connection lost, reconnecting: %v\n", err)
+
+			executor.reset()
+			err = executor.execute(c.Context, executor.w, ses, step.command)
+		}
+
+		// Server may close the connection before responding to shutdown.
+		if step.shutdown && err != nil && isConnectionLost(err) {
+			err = nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	executor.reset()
+
+	timeout := c.Duration("ready-timeout")
+	if timeout == 0 {
+		return nil
+	}
+
+	interval := c.Duration("ready-interval")
+
+	executor.waitDown(c.Context, executor.w, ses, interval)
+
+	return executor.waitReady(c.Context, executor.w, ses, profile.Probe, timeout, interval, false)
+}
+
+// waitDown waits until remote server stops answering after shutdown command,
+// not to report the stopping server as ready. Gives up after DefaultDownTimeout
+// because the server may restart faster than the interval.
+func (executor *Executor) waitDown(ctx context.Context, w io.Writer, ses *rconcli.Session, interval time.Duration) {
+	deadline := time.Now().Add(DefaultDownTimeout)
+
+	for time.Now().Before(deadline) {
+		if err := executor.Dial(ctx, ses); err != nil {
+			_, _ = fmt.Fprintf(w, "Server %s is down\n", ses.Address)
+
+			return
+		}
+
+		executor.reset()
+
+		if err := sleep(ctx, interval); err != nil {
+			return
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "Server %s is still up after %s\n", ses.Address, DefaultDownTimeout)
+}
+
+// restartProfile returns the game profile of the session with commands
+// overridden by flags.
+func restartProfile(c *cli.Context, ses *rconcli.Session) (game.Profile, error) {
+	var profile game.Profile
+
+	if ses.Game != "" {
+		var err error
+		if profile, err = game.Lookup(ses.Game); err != nil {
+			return profile, err
+		}
+	}
+
+	overrides := map[string]*string{
+		"broadcast": &profile.Broadcast,
+		"save":      &profile.Save,
+		"shutdown":  &profile.Shutdown,
+		"probe":     &profile.Probe,
+	}
+
+	for name, command := range overrides {
+		if c.IsSet(name) {
+			*command = c.String(name)
+		}
+	}
+
+	if profile.Shutdown == "" {
+		return profile, ErrEmptyShutdown
+	}
+
+	return profile, nil
+}
+
+// restartSteps returns countdown warnings every interval until shutdown,
+// save and shutdown commands.
+func restartSteps(c *cli.Context, profile *game.Profile) []restartStep {
+	var steps []restartStep
+
+	in, every := c.Duration("in"), c.Duration("warn-every")
+
+	if profile.Broadcast != "" {
+		for left := in; left > 0; left -= every {
+			message := strings.ReplaceAll(c.String("message"), LeftPlaceholder, formatLeft(left))
+			steps = append(steps, restartStep{at: in - left, command: profile.BroadcastCommand(message)})
+
+			if every <= 0 {
+				break
+			}
+		}
+	}
+
+	if profile.Save != "" {
+		steps = append(steps, restartStep{at: in, command: profile.Save})
+	}
+
+	return append(steps, restartStep{at: in, command: profile.Shutdown, shutdown: true})
+}
+
+// printRestart prints restart steps without executing them.
+func printRestart(
+	w io.Writer, ses *rconcli.Session, profile *game.Profile, steps []restartStep, timeout time.Duration,
+) {
+	name := profile.Name
+	if name == "" {
+		name = "(flags)"
+	}
+
+	_, _ = fmt.Fprint(w, "Dry run, nothing is sent to remote server.\n")
+	_, _ = fmt.Fprintf(w, "Restart %s (game %s):\n", ses.Address, name)
+
+	for _, step := range steps {
+		_, _ = fmt.Fprintf(w, "  +%s: %s\n", step.at, step.command)
+	}
+
+	if timeout > 0 {
+		_, _ = fmt.Fprintf(w, "Wait up to %s for the server to answer %q\n", timeout, profile.Probe)
+	}
+}
+
+// formatLeft returns human readable time left, e.g. "5 minutes".
+func formatLeft(d time.Duration) string {
+	if d < time.Second {
+		return d.String()
+	}
+
+	unit, value := "second", int64(d/time.Second)
+
+	if d >= time.Minute && d%time.Minute == 0 {
+		unit, value = "minute", int64(d/time.Minute)
+	}
+
+	if value == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", value, unit)
+}
+
+// isConnectionLost reports whether the error is caused by closed or broken
+// connection.
+func isConnectionLost(err error) bool {
+	switch classify(err, ExitCodeFailure) {
+	case ExitCodeNetwork, ExitCodeTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,151 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestart(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		commands []string
+		// down is the number of refused authentications after shutdown.
+		down int
+		// drop is the command after which the connection is dropped once.
+		drop string
+	)
+
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if down > 0 {
+				down--
+				rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, string([]byte{0x00})).WriteTo(c.Conn())
+
+				return
+			}
+
+			rcontest.AuthHandler(c)
+		}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			mu.Lock()
+			commands = append(commands, c.Request().Body())
+
+			if c.Request().Body() == drop {
+				drop = ""
+				mu.Unlock()
+
+				_ = c.Conn().(*net.TCPConn).CloseWrite()
+
+				return
+			}
+
+			// Stopping server does not respond to shutdown command.
+			if c.Request().Body() == "quit" {
+				down = 2
+				mu.Unlock()
+
+				return
+			}
+			mu.Unlock()
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	// Test game profile or shutdown command is required.
+	t.Run("no game", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "restart"})
+		assert.ErrorIs(t, err, executor.ErrEmptyShutdown)
+	})
+
+	t.Run("unknown game", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--game=pigeon", "restart"})
+		assert.ErrorIs(t, err, game.ErrUnknownGame)
+	})
+
+	// Test countdown is printed without connecting to remote server.
+	t.Run("dry run", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--game=zomboid",
+			"--dry-run", "restart", "--in=15m", "--message=Restart in {left}!", "--save=save now"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), `  +0s: servermsg "Restart in 15 minutes!"`)
+		assert.Contains(t, w.String(), `  +5m0s: servermsg "Restart in 10 minutes!"`)
+		assert.Contains(t, w.String(), `  +10m0s: servermsg "Restart in 5 minutes!"`)
+		assert.Contains(t, w.String(), "  +15m0s: save now\n  +15m0s: quit\n")
+		assert.Contains(t, w.String(), `Wait up to 5m0s for the server to answer "players"`)
+	})
+
+	// Test countdown, save and shutdown are executed and the restarted
+	// server is probed.
+	t.Run("restart", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--game=zomboid",
+			"--command-timeout=100ms", "restart", "--in=200ms", "--warn-every=100ms", "--ready-interval=50ms"})
+		assert.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, []string{
+			`servermsg "Server restart in 200ms"`,
+			`servermsg "Server restart in 100ms"`,
+			"save",
+			"quit",
+			"players",
+		}, commands)
+		assert.Contains(t, w.String(), "Server "+serverRCON.Addr()+" is down")
+		assert.Contains(t, w.String(), "is not ready (attempt 1)")
+		assert.Contains(t, w.String(), "is ready after")
+	})
+
+	// Test save is retried on a new connection if the connection is lost.
+	t.Run("reconnect", func(t *testing.T) {
+		mu.Lock()
+		commands, drop = nil, "save"
+		mu.Unlock()
+
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--game=zomboid",
+			"--command-timeout=100ms", "restart", "--in=0s", "--ready-timeout=0s"})
+		assert.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, []string{"save", "save", "quit"}, commands)
+		assert.Contains(t, w.String(), "Connection lost, reconnecting")
+	})
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/schedule"
+	"github.com/urfave/cli/v2"
+)
+
+// ErrEmptyScheduleFile is returned when schedule command is called without
+// schedule file name.
+var ErrEmptyScheduleFile = errors.New("schedule file is not set: to set schedule file add schedule path/to/schedule.yaml")
+
+// scheduleCommand returns the subcommand running commands by cron expressions.
+func (executor *Executor) scheduleCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "schedule",
+		Usage:     "Run commands on remote servers by cron expressions from the schedule file",
+		ArgsUsage: "<schedulefile>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "state",
+				Usage: "Path to the file saving last runs to handle runs missed while the scheduler was stopped",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Delay after which the run is missed, e.g. when the host was suspended",
+				Value: schedule.DefaultGracePeriod,
+			},
+		},
+		Action: executor.schedule,
+	}
+}
+
+// schedule executes when schedule subcommand is specified.
+func (executor *Executor) schedule(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return ErrEmptyScheduleFile
+	}
+
+	jobs, err := schedule.Load(name)
+	if err != nil {
+		return &ExitError{Code: ExitCodeConfig, Err: fmt.Errorf("schedule: %w", err)}
+	}
+
+	// Environments are checked on start to not fail at the scheduled time.
+	for _, job := range jobs.Jobs {
+		ses, err := executor.newSession(c, job.Env)
+		if err != nil {
+			return err
+		}
+
+		if !ses.HasAddress() && ses.Playback == "" {
+			return fmt.Errorf("%s: %s: %w", job.Name, job.Env, ErrEmptyAddress)
+		}
+	}
+
+	if isDryRun(c) {
+		printJobs(executor.w, jobs)
+
+		return nil
+	}
+
+	// Output of concurrent jobs is written by lines.
+	w := &syncWriter{w: executor.w}
+
+	scheduler, err := schedule.New(jobs, executor.runJob(c, w), w,
+		schedule.SetStateFile(c.String("state")),
+		schedule.SetGracePeriod(c.Duration("grace-period")),
+	)
+	if err != nil {
+		return fmt.Errorf("schedule: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(w, "Scheduler is running %d jobs from %s (press ^C to stop)\n", len(jobs.Jobs), name)
+
+	scheduler.Run(c.Context)
+
+	return nil
+}
+
+// runJob returns the function executing commands on the environment. Every
+// run uses its own executor, so concurrent jobs do not share connections.
+func (executor *Executor) runJob(c *cli.Context, w io.Writer) schedule.RunFunc {
+	return func(ctx context.Context, env string, commands ...string) error {
+		ses, err := executor.newSession(c, env)
+		if err != nil {
+			return err
+		}
+
+		job := NewExecutor(nil, w, executor.version)
+		job.output = executor.output
+
+		defer job.Close()
+
+		return job.Execute(ctx, w, ses, commands...)
+	}
+}
+
+// printJobs prints the next runs of jobs with their commands.
+func printJobs(w io.Writer, jobs *schedule.Schedule) {
+	now := time.Now()
+
+	for _, job := range jobs.Jobs {
+		_, _ = fmt.Fprintf(w, "%s: next run at %s on %s\n", job.Name, job.Next(now).Format(time.DateTime), job.Env)
+
+		for _, warning := range job.Countdown {
+			_, _ = fmt.Fprintf(w, "  countdown %s: %s\n", warning.Before, warning.Command)
+		}
+
+		for _, command := range job.Commands {
+			_, _ = fmt.Fprintf(w, "  %s\n", command)
+		}
+	}
+}
+
+// syncWriter serializes writes of concurrent jobs.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write writes p to the underlying writer.
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.w.Write(p)
+}
@@ -0,0 +1,93 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(handlersRCON),
+	)
+	defer serverRCON.Close()
+
+	configFileName := "rcon-schedule-test-local.yaml"
+	scheduleFileName := "schedule-test-local.yaml"
+	logFileName := "rcon-schedule-test.log"
+
+	createFile(configFileName, fmt.Sprintf(ConfigLayoutYAML, "rust", serverRCON.Addr(), "password", logFileName, "rcon"))
+	createFile(scheduleFileName, `jobs:
+  - name: help
+    cron: "* * * * * *"
+    env: rust
+    commands: ["help"]
+`)
+
+	defer func() {
+		os.Remove(configFileName)
+		os.Remove(scheduleFileName)
+		os.Remove(logFileName)
+	}()
+
+	// Test schedule file is required.
+	t.Run("empty schedule file", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "schedule"})
+		assert.ErrorIs(t, err, executor.ErrEmptyScheduleFile)
+		assert.Equal(t, executor.ExitCodeUsage, executor.ExitCode(err))
+	})
+
+	// Test missing config fails on start.
+	t.Run("config not found", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=" + configFileName + ".none", "schedule", scheduleFileName})
+		assert.Equal(t, executor.ExitCodeConfig, executor.ExitCode(err))
+	})
+
+	// Test next runs are printed without connecting to remote server.
+	t.Run("dry run", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=" + configFileName, "--dry-run", "schedule", scheduleFileName})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "help: next run at")
+		assert.Contains(t, w.String(), "on rust\n  help\n")
+	})
+
+	// Test jobs are executed and logged until the context is done.
+	t.Run("run jobs", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+
+		err := app.Run(ctx, []string{"", "-c=" + configFileName, "schedule", scheduleFileName})
+		assert.NoError(t, err)
+		assert.Contains(t, w.String(), "Scheduler is running 1 jobs")
+		assert.Contains(t, w.String(), "help: run started")
+		assert.Contains(t, w.String(), "Can I help you?")
+
+		log, err := os.ReadFile(logFileName)
+		assert.NoError(t, err)
+		assert.Contains(t, string(log), "Can I help you?")
+	})
+}
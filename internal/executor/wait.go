@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/urfave/cli/v2"
+)
+
+// Wait defaults.
+const (
+	// DefaultWaitTimeout is the default time to wait for remote server.
+	DefaultWaitTimeout = 5 * time.Minute
+
+	// DefaultWaitInterval is the default interval between attempts.
+	DefaultWaitInterval = 2 * time.Second
+)
+
+// waitCommand returns the subcommand blocking until remote server is ready.
+func (executor *Executor) waitCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "wait",
+		Usage: "Wait until remote server accepts connections and answers the probe command",
+		Description: "Exits with 0 when the server is ready and with timeout exit code when it is not ready in time. " +
+			"Progress is written to stderr.",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Time to wait for the server. Zero means wait forever",
+				Value: DefaultWaitTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Interval between attempts",
+				Value: DefaultWaitInterval,
+			},
+			&cli.StringFlag{
+				Name:  "probe",
+				Usage: "Command to execute after successful authentication. Probe of the game profile is used if not set",
+			},
+		},
+		Action: executor.wait,
+	}
+}
+
+// wait executes when wait subcommand is specified.
+func (executor *Executor) wait(c *cli.Context) error {
+	ses, err := executor.NewSession(c)
+	if err != nil {
+		return err
+	}
+
+	// Global timeout flag is shadowed by the wait timeout.
+	ses.Timeout = c.Lineage()[1].Duration("timeout")
+
+	if !ses.HasAddress() {
+		return ErrEmptyAddress
+	}
+
+	if ses.Password == "" {
+		return ErrEmptyPassword
+	}
+
+	probe := c.String("probe")
+	if probe == "" && ses.Game != "" {
+		profile, err := game.Lookup(ses.Game)
+		if err != nil {
+			return err
+		}
+
+		probe = profile.Probe
+	}
+
+	if isDryRun(c) {
+		var commands []string
+		if probe != "" {
+			commands = []string{probe}
+		}
+
+		return executor.dryRun(c, ses, commands, "none, server is ready when authentication succeeds")
+	}
+
+	return executor.waitReady(c.Context, c.App.ErrWriter, ses, probe, c.Duration("timeout"), c.Duration("interval"), true)
+}
@@ -0,0 +1,89 @@
+package executor_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/executor"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWait(t *testing.T) {
+	serverRCON := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() == "fail" {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID+1, "").WriteTo(c.Conn())
+
+				return
+			}
+
+			handlersRCON(c)
+		}),
+	)
+	defer serverRCON.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	closedAddr := listener.Addr().String()
+	listener.Close()
+
+	// Test ready server does not write to stdout.
+	t.Run("ready", func(t *testing.T) {
+		w := &bytes.Buffer{}
+
+		app := executor.NewExecutor(nil, w, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "wait", "--probe=help"})
+		assert.NoError(t, err)
+		assert.Empty(t, w.String())
+	})
+
+	t.Run("game probe", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=password", "--game=rust", "wait"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-c=", "wait"})
+		assert.ErrorIs(t, err, executor.ErrEmptyAddress)
+	})
+
+	// Test wrong password fails without waiting.
+	t.Run("auth failed", func(t *testing.T) {
+		app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+		defer app.Close()
+
+		err := app.Run(context.Background(), []string{"", "-a=" + serverRCON.Addr(), "-p=wrong", "wait"})
+		assert.ErrorIs(t, err, rcon.ErrAuthFailed)
+		assert.Equal(t, executor.ExitCodeAuth, executor.ExitCode(err))
+	})
+
+	// Test unreachable server and failed probe time out.
+	t.Run("timeout", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"-a=" + closedAddr, "-p=password", "wait"},
+			{"-a=" + serverRCON.Addr(), "-p=password", "wait", "--probe=fail"},
+		} {
+			app := executor.NewExecutor(nil, &bytes.Buffer{}, "")
+
+			err := app.Run(context.Background(), append([]string{""}, append(args, "--timeout=200ms", "--interval=50ms")...))
+			assert.ErrorIs(t, err, executor.ErrNotReady, args)
+			assert.Equal(t, executor.ExitCodeTimeout, executor.ExitCode(err), args)
+
+			app.Close()
+		}
+	})
+}
@@ -0,0 +1,269 @@
+// Package exporter periodically executes commands on remote servers and
+// exposes values extracted from responses as Prometheus gauges.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+// DefaultInterval is the default interval between collections.
+const DefaultInterval = 30 * time.Second
+
+// MetricPrefix is prepended to all exported metric names.
+const MetricPrefix = "rcon_"
+
+// ErrNoMatch is returned when metric regex does not match command response.
+var ErrNoMatch = errors.New("regex does not match response")
+
+// DialFunc creates an authorized connection to remote server.
+type DialFunc func(ses *rconcli.Session) (rconcli.Client, error)
+
+// Target is the environment to collect metrics from.
+type Target struct {
+	Env     string
+	Session *rconcli.Session
+
+	client  rconcli.Client
+	metrics []metric
+	// dialDuration is the duration of the last successful dial, exported
+	// on every collection of the connection.
+	dialDuration time.Duration
+}
+
+// metric is compiled rconcli.Metric.
+type metric struct {
+	rconcli.Metric
+	re *regexp.Regexp
+}
+
+// sample is a gauge value with labels.
+type sample struct {
+	name   string
+	help   string
+	labels string
+	value  float64
+}
+
+// Exporter collects metrics from targets and serves them in Prometheus
+// text exposition format.
+type Exporter struct {
+	targets []*Target
+	dial    DialFunc
+
+	mu sync.RWMutex
+	// samples contains samples of the last collection by target
+	// environment.
+	samples map[string][]sample
+}
+
+// New creates a new Exporter.
+func New(targets []*Target, dial DialFunc) (*Exporter, error) {
+	for _, target := range targets {
+		for _, m := range target.Session.Metrics {
+			re, err := regexp.Compile(m.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("metric %s: %w", m.Name, err)
+			}
+
+			if m.Help == "" {
+				m.Help = fmt.Sprintf("Value extracted from %q command response", m.Command)
+			}
+
+			target.metrics = append(target.metrics, metric{Metric: m, re: re})
+		}
+	}
+
+	return &Exporter{targets: targets, dial: dial, samples: make(map[string][]sample)}, nil
+}
+
+// Run collects metrics every interval until ctx is done.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.Collect()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect executes commands on all targets concurrently and updates gauges.
+func (e *Exporter) Collect() {
+	var wg sync.WaitGroup
+
+	for _, target := range e.targets {
+		wg.Add(1)
+
+		go func(target *Target) {
+			defer wg.Done()
+
+			e.collect(target)
+		}(target)
+	}
+
+	wg.Wait()
+}
+
+// Close closes connections to all targets.
+func (e *Exporter) Close() {
+	for _, target := range e.targets {
+		if target.client != nil {
+			_ = target.client.Close()
+			target.client = nil
+		}
+	}
+}
+
+// ServeHTTP writes collected metrics.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_ = e.Write(w)
+}
+
+// Write writes collected metrics in Prometheus text exposition format.
+func (e *Exporter) Write(w io.Writer) error {
+	e.mu.RLock()
+	var samples []sample
+
+	for _, target := range e.samples {
+		samples = append(samples, target...)
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].name != samples[j].name {
+			return samples[i].name < samples[j].name
+		}
+
+		return samples[i].labels < samples[j].labels
+	})
+
+	var b strings.Builder
+
+	for i, s := range samples {
+		if i == 0 || samples[i-1].name != s.name {
+			b.WriteString("# HELP " + s.name + " " + s.help + "\n")
+			b.WriteString("# TYPE " + s.name + " gauge\n")
+		}
+
+		b.WriteString(s.name + "{" + s.labels + "} " + strconv.FormatFloat(s.value, 'g', -1, 64) + "\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// collect dials the target if it is not connected and executes metric
+// commands. Connection is closed on error and reopened on next collection.
+// Samples of the previous collection are replaced, so values of failed
+// commands and unmatched regexes are not exported with stale values.
+func (e *Exporter) collect(target *Target) {
+	env := label("env", target.Env)
+
+	var samples []sample
+
+	defer func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.samples[target.Env] = samples
+	}()
+
+	add := func(name string, help string, labels string, value float64) {
+		samples = append(samples, sample{name: name, help: help, labels: labels, value: value})
+	}
+
+	if target.client == nil {
+		start := time.Now()
+
+		client, err := e.dial(target.Session)
+		if err != nil {
+			add(MetricPrefix+"up", "Whether the remote server is reachable", env, 0)
+
+			return
+		}
+
+		target.client = client
+		target.dialDuration = time.Since(start)
+	}
+
+	add(MetricPrefix+"dial_duration_seconds", "Duration of dial and auth to the remote server",
+		env, target.dialDuration.Seconds())
+
+	up := 1.0
+
+	responses := make(map[string]string)
+
+	for _, m := range target.metrics {
+		response, ok := responses[m.Command]
+		if !ok {
+			start := time.Now()
+
+			var err error
+			if response, err = target.client.Execute(m.Command); err != nil {
+				_ = target.client.Close()
+				target.client, up = nil, 0
+
+				break
+			}
+
+			responses[m.Command] = response
+			add(MetricPrefix+"command_duration_seconds", "Duration of the command execution",
+				env+","+label("command", m.Command), time.Since(start).Seconds())
+		}
+
+		if value, err := extract(m.re, response); err == nil {
+			add(MetricPrefix+m.Name, m.Help, env, value)
+		}
+	}
+
+	add(MetricPrefix+"up", "Whether the remote server is reachable", env, up)
+}
+
+// extract parses the first submatch or the whole match of re as float.
+func extract(re *regexp.Regexp, response string) (float64, error) {
+	matches := re.FindStringSubmatch(response)
+	if matches == nil {
+		return 0, ErrNoMatch
+	}
+
+	value := matches[0]
+	if len(matches) > 1 {
+		value = matches[1]
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse value: %w", err)
+	}
+
+	return f, nil
+}
+
+// label returns label pair with escaped value.
+func label(name string, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+
+	return name + `="` + value + `"`
+}
@@ -0,0 +1,120 @@
+package exporter_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/exporter"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+var errConnectionRefused = errors.New("connection refused")
+
+// client is rconcli.Client responding to status command.
+type client struct{}
+
+func (c *client) Execute(command string) (string, error) {
+	if command == "status" {
+		return "hostname: Rust Server\nplayers : 3 (500 max) (0 queued) (0 joining)", nil
+	}
+
+	return "", errConnectionRefused
+}
+
+func (c *client) Close() error {
+	return nil
+}
+
+func dial(ses *rconcli.Session) (rconcli.Client, error) {
+	if ses.Address == "" {
+		return nil, errConnectionRefused
+	}
+
+	return &client{}, nil
+}
+
+func TestNew(t *testing.T) {
+	t.Run("invalid regex", func(t *testing.T) {
+		targets := []*exporter.Target{{Env: "rust", Session: &rconcli.Session{
+			Metrics: []rconcli.Metric{{Name: "players", Command: "status", Regex: "players : ("}},
+		}}}
+
+		exp, err := exporter.New(targets, dial)
+		assert.Error(t, err)
+		assert.Nil(t, exp)
+	})
+}
+
+func TestExporter_Collect(t *testing.T) {
+	targets := []*exporter.Target{
+		{Env: "rust", Session: &rconcli.Session{Address: "127.0.0.1:28016", Metrics: []rconcli.Metric{
+			{Name: "players", Help: "Connected players", Command: "status", Regex: `players : (\d+)`},
+			{Name: "max_players", Command: "status", Regex: `\((\d+) max\)`},
+			{Name: "fps", Command: "fps", Regex: `\d+`},
+		}}},
+		{Env: "down", Session: &rconcli.Session{}},
+	}
+
+	exp, err := exporter.New(targets, dial)
+	assert.NoError(t, err)
+	defer exp.Close()
+
+	exp.Collect()
+
+	w := bytes.Buffer{}
+	assert.NoError(t, exp.Write(&w))
+
+	assert.Contains(t, w.String(), "# HELP rcon_players Connected players\n# TYPE rcon_players gauge\n"+
+		"rcon_players{env=\"rust\"} 3\n")
+	assert.Contains(t, w.String(), "rcon_max_players{env=\"rust\"} 500\n")
+	assert.Contains(t, w.String(), "rcon_command_duration_seconds{env=\"rust\",command=\"status\"}")
+	assert.Contains(t, w.String(), "rcon_up{env=\"down\"} 0\nrcon_up{env=\"rust\"} 0\n")
+	assert.NotContains(t, w.String(), "rcon_fps")
+
+	t.Run("http", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		exp.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, w.String(), recorder.Body.String())
+		assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+	})
+
+	t.Run("stale samples", func(t *testing.T) {
+		// The server goes down, values of the previous collection expire.
+		targets[0].Session.Address = ""
+
+		exp.Collect()
+
+		w := bytes.Buffer{}
+		assert.NoError(t, exp.Write(&w))
+
+		assert.Equal(t, "# HELP rcon_up Whether the remote server is reachable\n# TYPE rcon_up gauge\n"+
+			"rcon_up{env=\"down\"} 0\nrcon_up{env=\"rust\"} 0\n", w.String())
+	})
+}
+
+func TestExporter_CollectConnected(t *testing.T) {
+	targets := []*exporter.Target{
+		{Env: "rust", Session: &rconcli.Session{Address: "127.0.0.1:28016", Metrics: []rconcli.Metric{
+			{Name: "players", Command: "status", Regex: `players : (\d+)`},
+		}}},
+	}
+
+	exp, err := exporter.New(targets, dial)
+	assert.NoError(t, err)
+	defer exp.Close()
+
+	// The second collection reuses the connection and keeps dial duration.
+	for i := 0; i < 2; i++ {
+		exp.Collect()
+
+		w := bytes.Buffer{}
+		assert.NoError(t, exp.Write(&w))
+
+		assert.Contains(t, w.String(), "rcon_dial_duration_seconds{env=\"rust\"}")
+		assert.Contains(t, w.String(), "rcon_up{env=\"rust\"} 1\n")
+	}
+}
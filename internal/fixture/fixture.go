@@ -0,0 +1,221 @@
+// Package fixture implements recording of requests and responses to remote
+// server into fixture file and playback of recorded responses without
+// network connection.
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+var (
+	// ErrNoInteraction is returned when played back fixture has no recorded
+	// response for the command.
+	ErrNoInteraction = errors.New("no recorded interaction for command")
+
+	// ErrProtocolMismatch is returned when fixture recorded with one protocol
+	// is played back in session of another protocol.
+	ErrProtocolMismatch = errors.New("fixture protocol does not match session protocol")
+)
+
+// Interaction is a command sent to remote server and its response. Type and
+// Stacktrace are recorded from WebRCON response metadata.
+type Interaction struct {
+	Command    string        `json:"command" yaml:"command"`
+	Response   string        `json:"response" yaml:"response"`
+	Type       string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Stacktrace string        `json:"stacktrace,omitempty" yaml:"stacktrace,omitempty"`
+	Error      string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Duration   time.Duration `json:"duration" yaml:"duration"`
+}
+
+// Fixture contains interactions recorded on remote server.
+type Fixture struct {
+	Protocol     string        `json:"protocol" yaml:"protocol"`
+	Address      string        `json:"address" yaml:"address"`
+	RecordedAt   time.Time     `json:"recorded_at" yaml:"recorded_at"`
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Load reads fixture file from disk. YAML and JSON files are supported.
+func Load(name string) (*Fixture, error) {
+	fixture := new(Fixture)
+	if err := codec.ReadFile(name, fixture); err != nil {
+		return nil, err
+	}
+
+	return fixture, nil
+}
+
+// Save writes fixture file to disk. The format is chosen by file extension.
+func (fixture *Fixture) Save(name string) error {
+	return codec.WriteFile(name, fixture)
+}
+
+// Recorder is rconcli.Client which saves commands and responses of wrapped
+// client to the fixture.
+type Recorder struct {
+	client  rconcli.Client
+	fixture *Fixture
+	name    string
+}
+
+// NewRecorder creates a new Recorder. Fixture is written to file named name
+// on Close. The same fixture can be shared between several recorders to
+// collect interactions of reopened connections.
+func NewRecorder(client rconcli.Client, fixture *Fixture, name string) *Recorder {
+	return &Recorder{client: client, fixture: fixture, name: name}
+}
+
+// Execute sends command to wrapped client and records the response.
+func (r *Recorder) Execute(command string) (string, error) {
+	message, err := r.ExecuteMessage(command)
+
+	return message.Message, err
+}
+
+// ExecuteMessage sends command to wrapped client and records the response
+// with metadata. Responses of clients which do not implement
+// rconcli.MessageClient contain only the message.
+func (r *Recorder) ExecuteMessage(command string) (rconcli.Message, error) {
+	var (
+		message rconcli.Message
+		err     error
+	)
+
+	start := time.Now()
+
+	if mc, ok := r.client.(rconcli.MessageClient); ok {
+		message, err = mc.ExecuteMessage(command)
+	} else {
+		message.Message, err = r.client.Execute(command)
+	}
+
+	interaction := Interaction{
+		Command:    command,
+		Response:   message.Message,
+		Type:       message.Type,
+		Stacktrace: message.Stacktrace,
+		Duration:   time.Since(start),
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+
+	return message, err
+}
+
+// Close saves fixture file and closes wrapped client.
+func (r *Recorder) Close() error {
+	if err := r.fixture.Save(r.name); err != nil {
+		_ = r.client.Close()
+
+		return fmt.Errorf("record: %w", err)
+	}
+
+	return r.client.Close()
+}
+
+// Player is rconcli.Client which responds with recorded interactions.
+type Player struct {
+	fixture *Fixture
+	played  []bool
+	// latency delays responses by recorded durations.
+	latency bool
+}
+
+// PlayerOption allows to inject settings to Player.
+type PlayerOption func(p *Player)
+
+// SetLatency enables delaying responses by recorded durations of commands
+// to replay slow servers.
+func SetLatency(enabled bool) PlayerOption {
+	return func(p *Player) {
+		p.latency = enabled
+	}
+}
+
+// NewPlayer creates a new Player from fixture.
+func NewPlayer(fixture *Fixture, options ...PlayerOption) *Player {
+	p := &Player{fixture: fixture, played: make([]bool, len(fixture.Interactions))}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Open loads fixture file and creates a new Player.
+func Open(name string, options ...PlayerOption) (*Player, error) {
+	fixture, err := Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("playback: %w", err)
+	}
+
+	return NewPlayer(fixture, options...), nil
+}
+
+// CheckProtocol returns error if the fixture was recorded with another
+// protocol. Fixtures without protocol are played back in any session.
+func (p *Player) CheckProtocol(protocol string) error {
+	if p.fixture.Protocol != "" && p.fixture.Protocol != protocol {
+		return fmt.Errorf("playback: %w: recorded %q, session %q", ErrProtocolMismatch, p.fixture.Protocol, protocol)
+	}
+
+	return nil
+}
+
+// Fixture returns played back fixture.
+func (p *Player) Fixture() *Fixture {
+	return p.fixture
+}
+
+// Execute responds with the first not played interaction recorded for the
+// command. Recorded errors are returned as errors. The response is delayed
+// by the recorded duration if latency is enabled.
+func (p *Player) Execute(command string) (string, error) {
+	message, err := p.ExecuteMessage(command)
+
+	return message.Message, err
+}
+
+// ExecuteMessage responds like Execute with recorded response metadata.
+func (p *Player) ExecuteMessage(command string) (rconcli.Message, error) {
+	for i, interaction := range p.fixture.Interactions {
+		if p.played[i] || interaction.Command != command {
+			continue
+		}
+
+		p.played[i] = true
+
+		if p.latency {
+			time.Sleep(interaction.Duration)
+		}
+
+		message := rconcli.Message{
+			Message:    interaction.Response,
+			Type:       interaction.Type,
+			Stacktrace: interaction.Stacktrace,
+		}
+
+		if interaction.Error != "" {
+			return message, errors.New(interaction.Error) //nolint:goerr113 // Recorded error.
+		}
+
+		return message, nil
+	}
+
+	return rconcli.Message{}, fmt.Errorf("%w %q", ErrNoInteraction, command)
+}
+
+// Close does nothing. Player has no connection to close.
+func (p *Player) Close() error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package fixture_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+	"github.com/gorcon/rcon-cli/internal/fixture"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnknownCommand = errors.New("unknown command")
+
+// client is rconcli.Client responding to help command only.
+type client struct {
+	closed bool
+}
+
+func (c *client) Execute(command string) (string, error) {
+	if command == "help" {
+		return "Can I help you?", nil
+	}
+
+	return "", errUnknownCommand
+}
+
+func (c *client) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+// messageClient is rconcli.MessageClient responding with WebRCON metadata.
+type messageClient struct {
+	client
+}
+
+func (c *messageClient) ExecuteMessage(command string) (rconcli.Message, error) {
+	return rconcli.Message{Message: "NullReferenceException", Type: "Error", Stacktrace: "at Server.Run()"}, nil
+}
+
+func TestRecorder(t *testing.T) {
+	for _, fileName := range []string{"fixture-test-local.json", "fixture-test-local.yaml"} {
+		t.Run(fileName, func(t *testing.T) {
+			defer os.Remove(fileName)
+
+			inner := &client{}
+			recorder := fixture.NewRecorder(inner, &fixture.Fixture{Protocol: "rcon", Address: "127.0.0.1:16260"}, fileName)
+
+			result, err := recorder.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, "Can I help you?", result)
+
+			_, err = recorder.Execute("unknown")
+			assert.ErrorIs(t, err, errUnknownCommand)
+
+			assert.NoError(t, recorder.Close())
+			assert.True(t, inner.closed)
+
+			loaded, err := fixture.Load(fileName)
+			assert.NoError(t, err)
+			assert.Equal(t, "rcon", loaded.Protocol)
+
+			if assert.Len(t, loaded.Interactions, 2) {
+				assert.Equal(t, "help", loaded.Interactions[0].Command)
+				assert.Equal(t, "Can I help you?", loaded.Interactions[0].Response)
+				assert.Equal(t, "unknown command", loaded.Interactions[1].Error)
+			}
+		})
+	}
+
+	// Test message metadata is recorded and played back.
+	t.Run("message", func(t *testing.T) {
+		fileName := "fixture-test-local.json"
+		defer os.Remove(fileName)
+
+		recorder := fixture.NewRecorder(&messageClient{}, &fixture.Fixture{Protocol: "web"}, fileName)
+
+		expected := rconcli.Message{Message: "NullReferenceException", Type: "Error", Stacktrace: "at Server.Run()"}
+
+		message, err := recorder.ExecuteMessage("oxide.reload")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, message)
+		assert.NoError(t, recorder.Close())
+
+		player, err := fixture.Open(fileName)
+		assert.NoError(t, err)
+
+		message, err = player.ExecuteMessage("oxide.reload")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, message)
+	})
+
+	t.Run("unsupported file extension", func(t *testing.T) {
+		recorder := fixture.NewRecorder(&client{}, &fixture.Fixture{}, "fixture-test-local.ini")
+		assert.ErrorIs(t, recorder.Close(), codec.ErrUnsupportedFileExt)
+	})
+}
+
+func TestPlayer(t *testing.T) {
+	t.Run("file not exists", func(t *testing.T) {
+		player, err := fixture.Open("nonexist.json")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, player)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		player := fixture.NewPlayer(&fixture.Fixture{Interactions: []fixture.Interaction{
+			{Command: "players", Response: "Players connected (1):\n-admin"},
+			{Command: "help", Response: "Can I help you?"},
+			{Command: "players", Response: "Players connected (0):"},
+			{Command: "quit", Error: "permission denied"},
+		}})
+		defer player.Close()
+
+		result, err := player.Execute("players")
+		assert.NoError(t, err)
+		assert.Equal(t, "Players connected (1):\n-admin", result)
+
+		result, err = player.Execute("players")
+		assert.NoError(t, err)
+		assert.Equal(t, "Players connected (0):", result)
+
+		_, err = player.Execute("players")
+		assert.ErrorIs(t, err, fixture.ErrNoInteraction)
+
+		_, err = player.Execute("quit")
+		assert.EqualError(t, err, "permission denied")
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		recorded := &fixture.Fixture{Interactions: []fixture.Interaction{
+			{Command: "players", Response: "Players connected (0):", Duration: 50 * time.Millisecond},
+			{Command: "players", Response: "Players connected (0):", Duration: 50 * time.Millisecond},
+		}}
+
+		start := time.Now()
+		_, err := fixture.NewPlayer(recorded).Execute("players")
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+		start = time.Now()
+		_, err = fixture.NewPlayer(recorded, fixture.SetLatency(true)).Execute("players")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("protocol", func(t *testing.T) {
+		player := fixture.NewPlayer(&fixture.Fixture{Protocol: "rcon"})
+		assert.NoError(t, player.CheckProtocol("rcon"))
+		assert.ErrorIs(t, player.CheckProtocol("web"), fixture.ErrProtocolMismatch)
+
+		// Fixtures without protocol are played back in any session.
+		assert.NoError(t, fixture.NewPlayer(&fixture.Fixture{}).CheckProtocol("web"))
+	})
+}
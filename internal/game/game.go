@@ -0,0 +1,155 @@
+// Package game contains profiles of game specific commands used by
+// workflows like coordinated restarts.
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+)
+
+// MessagePlaceholder is replaced with the message in broadcast command.
+const MessagePlaceholder = "{message}"
+
+// ErrUnknownGame is returned when game profile is not found.
+var ErrUnknownGame = errors.New("unknown game")
+
+// Profile contains game specific commands. Empty command is not supported
+// by the game.
+type Profile struct {
+	Name string
+	// Broadcast is the command sending message to all players. The message
+	// replaces MessagePlaceholder.
+	Broadcast string
+	// Save is the command saving the world.
+	Save string
+	// Shutdown is the command stopping the server.
+	Shutdown string
+	// Probe is the harmless command checking the server is ready.
+	Probe string
+	// Players is the command listing connected players.
+	Players string
+	// Port is the default remote console port of the game.
+	Port int
+	// ResponseEnd is the strategy detecting the end of RCON responses split
+	// into multiple packets. It is empty for games reading single packets,
+	// because idle strategy delays every command.
+	ResponseEnd string
+
+	// parsePlayers parses the response of Players command.
+	parsePlayers func(response string) ([]Player, error)
+}
+
+// profiles contains known game profiles by names.
+var profiles = map[string]Profile{
+	"7dtd": {
+		Broadcast:    `say "{message}"`,
+		Save:         "saveworld",
+		Shutdown:     "shutdown",
+		Probe:        "version",
+		Players:      "lp",
+		Port:         8081,
+		parsePlayers: parseSevenDaysPlayers,
+	},
+	"ark": {
+		Broadcast:    "ServerChat {message}",
+		Save:         "SaveWorld",
+		Shutdown:     "DoExit",
+		Probe:        "ListPlayers",
+		Players:      "ListPlayers",
+		Port:         27020,
+		parsePlayers: parseARKPlayers,
+	},
+	"csgo": {
+		Broadcast:    "say {message}",
+		Shutdown:     "quit",
+		Probe:        "status",
+		Players:      "status",
+		Port:         27015,
+		ResponseEnd:  sourcercon.EndMirror,
+		parsePlayers: parseSourcePlayers,
+	},
+	"minecraft": {
+		Broadcast:    "say {message}",
+		Save:         "save-all",
+		Shutdown:     "stop",
+		Probe:        "list",
+		Players:      "list",
+		Port:         25575,
+		ResponseEnd:  sourcercon.EndSentinel,
+		parsePlayers: parseMinecraftPlayers,
+	},
+	"palworld": {
+		Broadcast:    "Broadcast {message}",
+		Save:         "Save",
+		Shutdown:     "DoExit",
+		Probe:        "Info",
+		Players:      "ShowPlayers",
+		Port:         25575,
+		parsePlayers: parsePalworldPlayers,
+	},
+	"rust": {
+		Broadcast:    `global.say "{message}"`,
+		Save:         "server.save",
+		Shutdown:     "quit",
+		Probe:        "status",
+		Players:      "status",
+		Port:         28016,
+		parsePlayers: parseRustPlayers,
+	},
+	"tf2": {
+		Broadcast:    "say {message}",
+		Shutdown:     "quit",
+		Probe:        "status",
+		Players:      "status",
+		Port:         27015,
+		ResponseEnd:  sourcercon.EndMirror,
+		parsePlayers: parseSourcePlayers,
+	},
+	"zomboid": {
+		Broadcast:    `servermsg "{message}"`,
+		Save:         "save",
+		Shutdown:     "quit",
+		Probe:        "players",
+		Players:      "players",
+		Port:         27015,
+		parsePlayers: parseZomboidPlayers,
+	},
+}
+
+// Lookup returns the game profile by name.
+func Lookup(name string) (Profile, error) {
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w %q: supported games are %s", ErrUnknownGame, name, strings.Join(Names(), ", "))
+	}
+
+	profile.Name = strings.ToLower(name)
+
+	return profile, nil
+}
+
+// Names returns sorted names of known games.
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// BroadcastCommand returns broadcast command with the message. Empty string
+// is returned if the game does not support broadcasts.
+func (profile *Profile) BroadcastCommand(message string) string {
+	if profile.Broadcast == "" {
+		return ""
+	}
+
+	return strings.ReplaceAll(profile.Broadcast, MessagePlaceholder, message)
+}
@@ -0,0 +1,51 @@
+package game_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	t.Run("unknown game", func(t *testing.T) {
+		_, err := game.Lookup("pigeon")
+		assert.ErrorIs(t, err, game.ErrUnknownGame)
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		profile, err := game.Lookup("Zomboid")
+		assert.NoError(t, err)
+		assert.Equal(t, "zomboid", profile.Name)
+		assert.Equal(t, "quit", profile.Shutdown)
+	})
+
+	// Test every profile can shut down, probe the server and list players.
+	t.Run("all profiles", func(t *testing.T) {
+		names := game.Names()
+		assert.True(t, sort.StringsAreSorted(names))
+
+		for _, name := range names {
+			profile, err := game.Lookup(name)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, profile.Shutdown, name)
+			assert.NotEmpty(t, profile.Probe, name)
+			assert.NotEmpty(t, profile.Players, name)
+			assert.NotZero(t, profile.Port, name)
+
+			// Idle strategy delays every command, so it is never the default.
+			assert.NotEqual(t, sourcercon.EndIdle, profile.ResponseEnd, name)
+		}
+	})
+}
+
+func TestProfile_BroadcastCommand(t *testing.T) {
+	profile, err := game.Lookup("rust")
+	assert.NoError(t, err)
+	assert.Equal(t, `global.say "Restart in 5 minutes"`, profile.BroadcastCommand("Restart in 5 minutes"))
+
+	profile.Broadcast = ""
+	assert.Equal(t, "", profile.BroadcastCommand("Restart in 5 minutes"))
+}
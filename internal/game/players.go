@@ -0,0 +1,351 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Players errors.
+var (
+	// ErrPlayersNotSupported is returned when the game profile has no parser
+	// of player list.
+	ErrPlayersNotSupported = errors.New("player list is not supported")
+
+	// ErrUnexpectedResponse is returned when the response is not a player
+	// list of the game.
+	ErrUnexpectedResponse = errors.New("unexpected player list response")
+)
+
+// Player is the connected player parsed from player list response. Fields
+// not provided by the game are left empty.
+type Player struct {
+	Name string `json:"name"`
+	// ID is the platform identifier of the player, e.g. Steam ID.
+	ID string `json:"id,omitempty"`
+	// Ping is the latency in milliseconds. It is zero if the game does not
+	// provide it, zero ping is exported too.
+	Ping int    `json:"ping"`
+	IP   string `json:"ip,omitempty"`
+	// Connected is the duration of the player session.
+	Connected time.Duration `json:"-"`
+}
+
+// MarshalJSON returns the player with connected time in seconds.
+func (player Player) MarshalJSON() ([]byte, error) {
+	type plain Player
+
+	return json.Marshal(struct {
+		plain
+		Connected int64 `json:"connected,omitempty"`
+	}{
+		plain:     plain(player),
+		Connected: int64(player.Connected / time.Second),
+	})
+}
+
+// ParsePlayers parses response of the Players command of the profile.
+func (profile *Profile) ParsePlayers(response string) ([]Player, error) {
+	if profile.parsePlayers == nil {
+		return nil, fmt.Errorf("%w for game %q", ErrPlayersNotSupported, profile.Name)
+	}
+
+	players, err := profile.parsePlayers(response)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", profile.Name, err)
+	}
+
+	return players, nil
+}
+
+// unexpected returns ErrUnexpectedResponse with the first line of response.
+func unexpected(response string) error {
+	line, _, _ := strings.Cut(strings.TrimSpace(response), "\n")
+
+	return fmt.Errorf("%w: %q", ErrUnexpectedResponse, line)
+}
+
+// lines returns trimmed non-empty lines of the response.
+func lines(response string) []string {
+	var result []string
+
+	for _, line := range strings.Split(response, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// host returns the address without port.
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+
+	return addr
+}
+
+// unquote returns the name captured between quotes with escape sequences
+// resolved, e.g. \" in Rust and Source status tables. Names with invalid
+// escape sequences are returned as is.
+func unquote(name string) string {
+	if unquoted, err := strconv.Unquote(`"` + name + `"`); err == nil {
+		return unquoted
+	}
+
+	return name
+}
+
+// parseClock parses connected time in [hh:]mm:ss format.
+func parseClock(value string) time.Duration {
+	var d time.Duration
+
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+
+		d = d*60 + time.Duration(n)
+	}
+
+	return d * time.Second
+}
+
+// parseZomboidPlayers parses Project Zomboid players response:
+//
+//	Players connected (2):
+//	-admin
+//	-survivor
+func parseZomboidPlayers(response string) ([]Player, error) {
+	rows := lines(response)
+	if len(rows) == 0 || !strings.HasPrefix(rows[0], "Players connected (") {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, row := range rows[1:] {
+		if name, ok := strings.CutPrefix(row, "-"); ok {
+			players = append(players, Player{Name: name})
+		}
+	}
+
+	return players, nil
+}
+
+// rustPlayerRow matches the row of Rust status table:
+//
+//	id                name     ping connected addr               owner violation kicks
+//	76561198000000001 "Player" 52   102.45s   203.0.113.5:56789        0.0       0
+var rustPlayerRow = regexp.MustCompile(`^(\d+)\s+"(.*)"\s+(\d+)\s+(\S+)\s+(\S+)`)
+
+// parseRustPlayers parses the players table of Rust status response.
+func parseRustPlayers(response string) ([]Player, error) {
+	rows := lines(response)
+
+	header := -1
+
+	for i, row := range rows {
+		if strings.HasPrefix(row, "id ") && strings.Contains(row, " name ") {
+			header = i
+
+			break
+		}
+	}
+
+	if header == -1 {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, row := range rows[header+1:] {
+		match := rustPlayerRow.FindStringSubmatch(row)
+		if match == nil {
+			continue
+		}
+
+		ping, _ := strconv.Atoi(match[3])
+		connected, _ := time.ParseDuration(match[4])
+
+		players = append(players, Player{
+			Name:      unquote(match[2]),
+			ID:        match[1],
+			Ping:      ping,
+			IP:        host(match[5]),
+			Connected: connected.Truncate(time.Second),
+		})
+	}
+
+	return players, nil
+}
+
+// sevenDaysField matches key=value pairs of 7 Days to Die player row.
+var sevenDaysField = regexp.MustCompile(`(\w+)=([^,]*)`)
+
+// parseSevenDaysPlayers parses 7 Days to Die lp response. Player rows are
+// like "0. id=171, name=Player, pos=(-1.5, 61.1, 20.3), ..., ip=203.0.113.5,
+// ping=12" and the list ends with "Total of 1 in the game".
+func parseSevenDaysPlayers(response string) ([]Player, error) {
+	if !strings.Contains(response, "Total of ") {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, row := range lines(response) {
+		index, fields, ok := strings.Cut(row, ". id=")
+		if _, err := strconv.Atoi(index); !ok || err != nil {
+			continue
+		}
+
+		values := map[string]string{}
+		for _, match := range sevenDaysField.FindAllStringSubmatch("id="+fields, -1) {
+			values[match[1]] = strings.TrimSpace(match[2])
+		}
+
+		id := values["pltfmid"]
+		if id == "" {
+			id = values["steamid"]
+		}
+
+		ping, _ := strconv.Atoi(values["ping"])
+
+		players = append(players, Player{Name: values["name"], ID: id, Ping: ping, IP: values["ip"]})
+	}
+
+	return players, nil
+}
+
+// minecraftList matches Minecraft list response:
+//
+//	There are 2 of a max of 20 players online: Steve, Alex
+var minecraftList = regexp.MustCompile(`^There are \d+ of a max (?:of )?\d+ players online:(.*)$`)
+
+// parseMinecraftPlayers parses Minecraft list response.
+func parseMinecraftPlayers(response string) ([]Player, error) {
+	match := minecraftList.FindStringSubmatch(strings.TrimSpace(response))
+	if match == nil {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, name := range strings.Split(match[1], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			players = append(players, Player{Name: name})
+		}
+	}
+
+	return players, nil
+}
+
+// arkPlayerRow matches the row of ARK ListPlayers response:
+//
+//  0. Player, 76561198000000001
+var arkPlayerRow = regexp.MustCompile(`^\d+\. (.*), (\S+)$`)
+
+// parseARKPlayers parses ARK ListPlayers response.
+func parseARKPlayers(response string) ([]Player, error) {
+	rows := lines(response)
+	players := []Player{}
+
+	if len(rows) == 1 && strings.EqualFold(rows[0], "No Players Connected") {
+		return players, nil
+	}
+
+	for _, row := range rows {
+		match := arkPlayerRow.FindStringSubmatch(row)
+		if match == nil {
+			return nil, unexpected(response)
+		}
+
+		players = append(players, Player{Name: match[1], ID: match[2]})
+	}
+
+	return players, nil
+}
+
+// parsePalworldPlayers parses Palworld ShowPlayers csv response:
+//
+//	name,playeruid,steamid
+//	Player,1234567890,76561198000000001
+func parsePalworldPlayers(response string) ([]Player, error) {
+	rows := lines(response)
+	if len(rows) == 0 || rows[0] != "name,playeruid,steamid" {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, row := range rows[1:] {
+		fields := strings.Split(row, ",")
+		if len(fields) < 3 {
+			return nil, unexpected(row)
+		}
+
+		// Name may contain commas.
+		n := len(fields)
+		players = append(players, Player{Name: strings.Join(fields[:n-2], ","), ID: fields[n-1]})
+	}
+
+	return players, nil
+}
+
+// sourcePlayerRow matches the player row of Source engine status response,
+// with optional slot column of CS:GO and rate column:
+//
+//	# userid name uniqueid connected ping loss state rate adr
+//	#      2 "Player" [U:1:12345] 05:12 45 0 active 196608 203.0.113.5:27005
+var sourcePlayerRow = regexp.MustCompile(
+	`^#\s+\d+(?:\s+\d+)?\s+"(.*)"\s+(\S+)\s+([\d:]+)\s+(\d+)\s+\d+\s+\S+(?:\s+\d+)?\s+(\S+)$`,
+)
+
+// parseSourcePlayers parses the players of Source engine status response.
+// Bots are skipped.
+func parseSourcePlayers(response string) ([]Player, error) {
+	rows := lines(response)
+
+	header := -1
+
+	for i, row := range rows {
+		if strings.HasPrefix(row, "# userid") {
+			header = i
+
+			break
+		}
+	}
+
+	if header == -1 {
+		return nil, unexpected(response)
+	}
+
+	players := []Player{}
+
+	for _, row := range rows[header+1:] {
+		match := sourcePlayerRow.FindStringSubmatch(row)
+		if match == nil {
+			continue
+		}
+
+		ping, _ := strconv.Atoi(match[4])
+
+		players = append(players, Player{
+			Name:      unquote(match[1]),
+			ID:        match[2],
+			Ping:      ping,
+			IP:        host(match[5]),
+			Connected: parseClock(match[3]),
+		})
+	}
+
+	return players, nil
+}
@@ -0,0 +1,170 @@
+package game_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_ParsePlayers(t *testing.T) {
+	tests := []struct {
+		name     string
+		game     string
+		response string
+		want     []game.Player
+	}{
+		{
+			name:     "zomboid",
+			game:     "zomboid",
+			response: "Players connected (2): \n-admin\n-Survivor Bob\n",
+			want:     []game.Player{{Name: "admin"}, {Name: "Survivor Bob"}},
+		},
+		{
+			name:     "zomboid empty",
+			game:     "zomboid",
+			response: "Players connected (0):",
+			want:     []game.Player{},
+		},
+		{
+			name: "rust",
+			game: "rust",
+			response: `hostname: Rust Server [DOCKER]
+version : 2260 secure (secure mode enabled, connected to Steam3)
+map     : Procedural Map
+players : 2 (500 max) (0 queued) (0 joining)
+
+id                name            ping connected addr                owner violation kicks
+76561198000000001 "Player"        52   102.45s   203.0.113.5:56789         0.0       0
+76561198000000002 "Big \"Bad\" Wolf" 120  3600s     [2001:db8::1]:1234        0.0       0
+`,
+			want: []game.Player{
+				{Name: "Player", ID: "76561198000000001", Ping: 52, IP: "203.0.113.5", Connected: 102 * time.Second},
+				{Name: `Big "Bad" Wolf`, ID: "76561198000000002", Ping: 120, IP: "2001:db8::1", Connected: time.Hour},
+			},
+		},
+		{
+			name: "rust empty",
+			game: "rust",
+			response: `hostname: Rust Server [DOCKER]
+players : 0 (500 max) (0 queued) (0 joining)
+id name ping connected addr owner violation kicks`,
+			want: []game.Player{},
+		},
+		{
+			name: "7dtd",
+			game: "7dtd",
+			response: "2020-11-14T23:09:20 31220.643 INF Executing command 'lp' by Telnet from 127.0.0.1:51000\n" +
+				"0. id=171, name=Player, pos=(-1.5, 61.1, 20.3), rot=(0.0, 90.0, 0.0), remote=True, health=100, " +
+				"deaths=0, zombies=3, players=0, score=3, level=2, pltfmid=Steam_76561198000000001, crossid=EOS_0002, " +
+				"ip=203.0.113.5, ping=12\n" +
+				"1. id=172, name=Old, pos=(0.0, 0.0, 0.0), steamid=76561198000000002, ip=203.0.113.6, ping=40\n" +
+				"Total of 2 in the game",
+			want: []game.Player{
+				{Name: "Player", ID: "Steam_76561198000000001", Ping: 12, IP: "203.0.113.5"},
+				{Name: "Old", ID: "76561198000000002", Ping: 40, IP: "203.0.113.6"},
+			},
+		},
+		{
+			name:     "minecraft",
+			game:     "minecraft",
+			response: "There are 2 of a max of 20 players online: Steve, Alex",
+			want:     []game.Player{{Name: "Steve"}, {Name: "Alex"}},
+		},
+		{
+			name:     "minecraft empty",
+			game:     "minecraft",
+			response: "There are 0 of a max 20 players online: ",
+			want:     []game.Player{},
+		},
+		{
+			name:     "ark",
+			game:     "ark",
+			response: "0. Player, 76561198000000001\n1. Comma, Name, 76561198000000002\n",
+			want: []game.Player{
+				{Name: "Player", ID: "76561198000000001"},
+				{Name: "Comma, Name", ID: "76561198000000002"},
+			},
+		},
+		{
+			name:     "ark empty",
+			game:     "ark",
+			response: "No Players Connected",
+			want:     []game.Player{},
+		},
+		{
+			name:     "palworld",
+			game:     "palworld",
+			response: "name,playeruid,steamid\nPlayer,1234567890,76561198000000001\n",
+			want:     []game.Player{{Name: "Player", ID: "76561198000000001"}},
+		},
+		{
+			name: "tf2",
+			game: "tf2",
+			response: `hostname: Team Fortress
+map     : ctf_2fort at: 0 x, 0 y, 0 z
+players : 2 humans, 1 bots (24 max)
+# userid name                uniqueid            connected ping loss state  adr
+#      2 "Player"            [U:1:12345]         05:12       45    0 active 203.0.113.5:27005
+#      3 "Night Owl"         [U:1:67890]      1:02:03       80    0 active 203.0.113.6:27005
+#      4 "Bot"               BOT                                   active
+`,
+			want: []game.Player{
+				{Name: "Player", ID: "[U:1:12345]", Ping: 45, IP: "203.0.113.5", Connected: 5*time.Minute + 12*time.Second},
+				{Name: "Night Owl", ID: "[U:1:67890]", Ping: 80, IP: "203.0.113.6", Connected: time.Hour + 2*time.Minute + 3*time.Second},
+			},
+		},
+		{
+			name: "csgo",
+			game: "csgo",
+			response: `# userid name uniqueid connected ping loss state rate adr
+#  2 1 "Player" STEAM_1:0:12345 05:12 45 0 active 786432 203.0.113.5:27005
+#end`,
+			want: []game.Player{
+				{Name: "Player", ID: "STEAM_1:0:12345", Ping: 45, IP: "203.0.113.5", Connected: 5*time.Minute + 12*time.Second},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			profile, err := game.Lookup(test.game)
+			assert.NoError(t, err)
+
+			players, err := profile.ParsePlayers(test.response)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, players)
+		})
+	}
+
+	// Test error responses are not parsed as empty lists.
+	t.Run("unexpected response", func(t *testing.T) {
+		for _, name := range game.Names() {
+			profile, err := game.Lookup(name)
+			assert.NoError(t, err)
+
+			_, err = profile.ParsePlayers("Unknown command")
+			assert.ErrorIs(t, err, game.ErrUnexpectedResponse, name)
+		}
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		var profile game.Profile
+
+		_, err := profile.ParsePlayers("players")
+		assert.ErrorIs(t, err, game.ErrPlayersNotSupported)
+	})
+}
+
+func TestPlayer_MarshalJSON(t *testing.T) {
+	js, err := json.Marshal(game.Player{Name: "Player", ID: "76561198000000001", Ping: 52, Connected: 90 * time.Second})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Player","id":"76561198000000001","ping":52,"connected":90}`, string(js))
+
+	js, err = json.Marshal(game.Player{Name: "Steve"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Steve","ping":0}`, string(js))
+}
@@ -0,0 +1,403 @@
+// Package gateway implements HTTP API to execute commands on remote servers
+// from configured environments.
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/pool"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	gorilla "github.com/gorilla/websocket"
+)
+
+// Routes prefixes.
+const (
+	RouteEnvs = "/v1/envs"
+)
+
+// Route actions of the environment.
+const (
+	ActionExec    = "exec"
+	ActionConsole = "console"
+)
+
+// MaxRequestSize is the max size of exec request body.
+const MaxRequestSize = 1 << 20
+
+var (
+	// ErrUnauthorized is returned when request has no valid bearer token.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden is returned when token is not allowed to access
+	// the environment.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrNotFound is returned when requested route or environment does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrMethodNotAllowed is returned when route does not support request method.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+
+	// ErrCommandEmpty is returned when executed command length equal 0.
+	ErrCommandEmpty = errors.New("command is not set")
+
+	// ErrNotConfirmed is returned when command requiring confirmation was
+	// not confirmed by the request.
+	ErrNotConfirmed = errors.New("command is not confirmed: to confirm set confirm in the request")
+)
+
+// DialFunc creates an authorized connection to remote server.
+type DialFunc func(ses *rconcli.Session) (rconcli.Client, error)
+
+// ExecRequest is the body of exec request.
+type ExecRequest struct {
+	Commands []string `json:"commands"`
+	// Confirm allows commands requiring confirmation in the environment.
+	Confirm bool `json:"confirm"`
+}
+
+// Result is the response of the command.
+type Result struct {
+	Command  string `json:"command"`
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ExecResponse is the body of exec response. Error is set if results are
+// incomplete because the remote server or the pool failed.
+type ExecResponse struct {
+	Results []Result `json:"results"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// EnvsResponse is the body of environments list response.
+type EnvsResponse struct {
+	Envs []string `json:"envs"`
+}
+
+// ErrorResponse is the body of failed request response.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Gateway is http.Handler executing commands on remote servers.
+type Gateway struct {
+	sessions map[string]*rconcli.Session
+	tokens   Tokens
+	pool     *pool.Pool
+	upgrader gorilla.Upgrader
+}
+
+// New creates a new Gateway for sessions of environments. Connections are
+// pooled by environment name with options.
+func New(sessions map[string]*rconcli.Session, tokens Tokens, dial DialFunc, options ...pool.Option) *Gateway {
+	g := &Gateway{
+		sessions: sessions,
+		tokens:   tokens,
+		upgrader: gorilla.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+	}
+
+	g.pool = pool.New(func(env string) (rconcli.Client, error) {
+		return dial(g.sessions[env])
+	}, options...)
+
+	return g
+}
+
+// ServeHTTP routes requests to the handlers.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := g.authorize(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+
+		return
+	}
+
+	if r.URL.Path == RouteEnvs {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+
+			return
+		}
+
+		g.envs(w, token)
+
+		return
+	}
+
+	route, found := strings.CutPrefix(r.URL.Path, RouteEnvs+"/")
+	if !found {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+
+		return
+	}
+
+	// Unknown environments are forbidden too, so tokens cannot find out
+	// environments they are not allowed to.
+	env, action, _ := strings.Cut(route, "/")
+	if _, ok := g.sessions[env]; !ok || !token.Allowed(env) {
+		writeError(w, http.StatusForbidden, ErrForbidden)
+
+		return
+	}
+
+	switch {
+	case action == ActionExec && r.Method == http.MethodPost:
+		g.exec(w, r, env, token.Role)
+	case action == ActionConsole && r.Method == http.MethodGet:
+		g.console(w, r, env, token.Role)
+	case action == ActionExec || action == ActionConsole:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+	default:
+		writeError(w, http.StatusNotFound, ErrNotFound)
+	}
+}
+
+// Execute executes commands on the environment remote server as the role.
+// Commands requiring confirmation are refused unless confirm is true. Refused
+// commands are not sent, the remote server is not dialed if all commands are
+// refused. Commands are not interleaved with commands of other requests.
+// Connection is reopened if command failed. Error is returned with results
+// if dial failed or the pool cannot provide the connection, commands which
+// were not sent have the error in their results.
+func (g *Gateway) Execute(env string, role string, confirm bool, commands ...string) ([]Result, error) {
+	ses := g.sessions[env]
+	results := make([]Result, len(commands))
+	pending := make([]int, 0, len(commands))
+
+	for i, command := range commands {
+		results[i].Command = command
+
+		if command == "" {
+			results[i].Error = ErrCommandEmpty.Error()
+
+			continue
+		}
+
+		if refused := refuse(ses, role, confirm, command); refused != nil {
+			_ = rconcli.WriteAudit(ses.ResolveAuditLog(), ses.Address, role, command, refused.Error())
+			results[i].Error = refused.Error()
+
+			continue
+		}
+
+		pending = append(pending, i)
+	}
+
+	for len(pending) != 0 {
+		// called is false if the pool failed before calling fn, e.g. the pool
+		// is exhausted or closed, so no commands are consumed.
+		called := false
+
+		err := g.pool.Do(env, func(client rconcli.Client) error {
+			called = true
+
+			for len(pending) != 0 {
+				result := &results[pending[0]]
+				pending = pending[1:]
+
+				response, err := client.Execute(result.Command)
+				result.Response = strings.TrimSpace(response)
+
+				_ = rconcli.WriteLog(ses.Log, ses.Address, result.Command, result.Response)
+
+				if err != nil {
+					result.Error = err.Error()
+
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil && !called {
+			var dialErr *pool.DialError
+			if errors.As(err, &dialErr) {
+				err = dialErr.Err
+			}
+
+			// Results of executed commands are returned with the error.
+			for _, i := range pending {
+				results[i].Error = err.Error()
+			}
+
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// unavailable returns true if the error means that the pool cannot provide
+// the connection now, so the request can be retried later.
+func unavailable(err error) bool {
+	return errors.Is(err, pool.ErrPoolExhausted) || errors.Is(err, pool.ErrPoolClosed)
+}
+
+// Close closes connections to remote servers.
+func (g *Gateway) Close() {
+	_ = g.pool.Close()
+}
+
+// authorize returns the token from Authorization header with Bearer scheme.
+// Browsers cannot set headers to websocket connections, so access_token query
+// parameter is accepted for console upgrade requests only.
+func (g *Gateway) authorize(r *http.Request) (Token, bool) {
+	var key string
+
+	if header := r.Header.Get("Authorization"); header != "" {
+		var found bool
+		if key, found = strings.CutPrefix(header, "Bearer "); !found {
+			return Token{}, false
+		}
+	} else if isConsoleUpgrade(r) {
+		key = r.URL.Query().Get("access_token")
+	}
+
+	if key == "" {
+		return Token{}, false
+	}
+
+	// Compare all tokens in constant time to not leak their prefixes.
+	var (
+		found Token
+		ok    bool
+	)
+
+	for secret, token := range g.tokens {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(key)) == 1 {
+			found, ok = token, true
+		}
+	}
+
+	return found, ok
+}
+
+// isConsoleUpgrade returns true if the request upgrades to websocket console
+// of the environment.
+func isConsoleUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/"+ActionConsole) &&
+		gorilla.IsWebSocketUpgrade(r)
+}
+
+// envs writes the list of environments allowed for the token.
+func (g *Gateway) envs(w http.ResponseWriter, token Token) {
+	envs := make([]string, 0, len(g.sessions))
+
+	for env := range g.sessions {
+		if token.Allowed(env) {
+			envs = append(envs, env)
+		}
+	}
+
+	sort.Strings(envs)
+
+	writeJSON(w, http.StatusOK, EnvsResponse{Envs: envs})
+}
+
+// exec executes commands from request body.
+func (g *Gateway) exec(w http.ResponseWriter, r *http.Request, env string, role string) {
+	var request ExecRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+
+		return
+	}
+
+	if len(request.Commands) == 0 {
+		writeError(w, http.StatusBadRequest, ErrCommandEmpty)
+
+		return
+	}
+
+	results, err := g.Execute(env, role, request.Confirm, request.Commands...)
+	if err != nil {
+		status := http.StatusBadGateway
+		if unavailable(err) {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, ExecResponse{Results: results, Error: err.Error()})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ExecResponse{Results: results})
+}
+
+// console executes commands received from websocket connection and writes
+// results back as json messages. Commands requiring confirmation are allowed
+// if the connection is opened with confirm=true query parameter.
+func (g *Gateway) console(w http.ResponseWriter, r *http.Request, env string, role string) {
+	confirm := r.URL.Query().Get("confirm") == "true"
+
+	ws, err := g.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	for {
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		command := strings.TrimSpace(string(p))
+
+		result := Result{Command: command}
+
+		results, err := g.Execute(env, role, confirm, command)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result = results[0]
+		}
+
+		if err := ws.WriteJSON(result); err != nil {
+			return
+		}
+
+		// Close code 1013 is the websocket counterpart of 503 status.
+		if unavailable(err) {
+			message := gorilla.FormatCloseMessage(gorilla.CloseTryAgainLater, err.Error())
+			_ = ws.WriteControl(gorilla.CloseMessage, message, time.Now().Add(time.Second))
+
+			return
+		}
+	}
+}
+
+// refuse returns the reason the command is refused or nil if it is allowed.
+func refuse(ses *rconcli.Session, role string, confirm bool, command string) error {
+	if err := ses.Refuse(role, command); err != nil {
+		return err
+	}
+
+	if !confirm && ses.NeedsConfirm(command) {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
+
+// writeJSON writes value as json response.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes error as json response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
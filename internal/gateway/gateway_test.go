@@ -0,0 +1,418 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/gateway"
+	"github.com/gorcon/rcon-cli/internal/pool"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+const TokensYAML = `panel:
+  envs: ["rust"]
+admin:
+  envs: ["*"]
+`
+
+var (
+	errConnectionRefused = errors.New("connection refused")
+	errConnectionLost    = errors.New("connection lost")
+	errUnknownCommand    = errors.New("unknown command")
+)
+
+// client is rconcli.Client responding to status command.
+type client struct{}
+
+func (c *client) Execute(command string) (string, error) {
+	if command == "status" {
+		return "players : 0 (500 max)\n", nil
+	}
+
+	return "", errUnknownCommand
+}
+
+func (c *client) Close() error {
+	return nil
+}
+
+func dial(ses *rconcli.Session) (rconcli.Client, error) {
+	if ses.Address == "" {
+		return nil, errConnectionRefused
+	}
+
+	return &client{}, nil
+}
+
+func TestNewTokens(t *testing.T) {
+	t.Run("file not exists", func(t *testing.T) {
+		tokens, err := gateway.NewTokens("nonexist.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, tokens)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		fileName := "tokens-test-local.yaml"
+		createFile(fileName, TokensYAML)
+		defer os.Remove(fileName)
+
+		tokens, err := gateway.NewTokens(fileName)
+		assert.NoError(t, err)
+		assert.True(t, tokens["panel"].Allowed("rust"))
+		assert.False(t, tokens["panel"].Allowed("zomboid"))
+		assert.True(t, tokens["admin"].Allowed("zomboid"))
+	})
+}
+
+func TestGateway(t *testing.T) {
+	tokens := gateway.Tokens{
+		"panel":     {Envs: []string{"rust", "down"}},
+		"moderator": {Envs: []string{"rust"}, Role: "moderator"},
+		"admin":     {Envs: []string{gateway.AllEnvs}},
+	}
+	sessions := map[string]*rconcli.Session{
+		"rust": {
+			Address: "127.0.0.1:28016",
+			Deny:    []string{"^quit"},
+			Confirm: []string{"^kick "},
+			Roles:   map[string]rconcli.Role{"moderator": {Allow: []string{"^(kick|ban|say) "}}},
+		},
+		"zomboid": {Address: "127.0.0.1:16260"},
+		"down":    {Protected: true},
+	}
+
+	gw := gateway.New(sessions, tokens, dial)
+	defer gw.Close()
+
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	request := func(method string, path string, token string, body string) (int, string) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var data json.RawMessage
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+
+		return resp.StatusCode, string(data)
+	}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		status, body := request(http.MethodGet, gateway.RouteEnvs, "wrong", "")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, `{"error":"unauthorized"}`, body)
+	})
+
+	t.Run("unauthorized scheme", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+gateway.RouteEnvs, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Basic panel")
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		req.Header.Set("Authorization", "panel")
+
+		resp, err = http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	// Test access token in query is accepted for console upgrade only.
+	t.Run("unauthorized query", func(t *testing.T) {
+		status, _ := request(http.MethodGet, gateway.RouteEnvs+"?access_token=panel", "", "")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = request(http.MethodPost, "/v1/envs/rust/exec?access_token=panel", "", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = request(http.MethodGet, "/v1/envs/rust/console?access_token=panel", "", "")
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("envs", func(t *testing.T) {
+		status, body := request(http.MethodGet, gateway.RouteEnvs, "panel", "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"envs":["down","rust"]}`, body)
+
+		_, body = request(http.MethodGet, gateway.RouteEnvs, "admin", "")
+		assert.Equal(t, `{"envs":["down","rust","zomboid"]}`, body)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		status, _ := request(http.MethodPost, "/v1/envs/zomboid/exec", "panel", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	// Test unknown environment is not distinguished from forbidden one.
+	t.Run("unknown env", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/minecraft/exec", "admin", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, `{"error":"forbidden"}`, body)
+
+		status, _ = request(http.MethodPost, "/v1/envs/minecraft/exec", "wrong", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		status, _ := request(http.MethodPost, "/v1/envs/rust/unknown", "admin", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		status, _ := request(http.MethodGet, "/v1/envs/rust/exec", "admin", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		status, _ := request(http.MethodPost, "/v1/envs/rust/exec", "panel", `{"commands":[]}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("bad gateway", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/down/exec", "panel", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.Equal(t, `{"results":[{"command":"status","response":"","error":"connection refused"}],`+
+			`"error":"connection refused"}`, body)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/rust/exec", "panel", `{"commands":["status","help"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"status","response":"players : 0 (500 max)"},`+
+			`{"command":"help","response":"","error":"unknown command"}]}`, body)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/rust/exec", "panel", `{"commands":["quit","status"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"quit","response":"","error":"command is denied"},`+
+			`{"command":"status","response":"players : 0 (500 max)"}]}`, body)
+	})
+
+	t.Run("role", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/rust/exec", "moderator", `{"commands":["status"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"status","response":"","error":"command is not allowed for role"}]}`, body)
+	})
+
+	t.Run("not confirmed", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/rust/exec", "panel", `{"commands":["kick bob"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"kick bob","response":"",`+
+			`"error":"command is not confirmed: to confirm set confirm in the request"}]}`, body)
+
+		status, body = request(http.MethodPost, "/v1/envs/rust/exec", "panel", `{"commands":["kick bob"],"confirm":true}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"kick bob","response":"","error":"unknown command"}]}`, body)
+	})
+
+	t.Run("refused not dialed", func(t *testing.T) {
+		status, body := request(http.MethodPost, "/v1/envs/down/exec", "panel", `{"commands":["restart"]}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"results":[{"command":"restart","response":"",`+
+			`"error":"command is not confirmed: to confirm set confirm in the request"}]}`, body)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/envs/rust/console?access_token=panel"
+
+		ws, _, err := gorilla.DefaultDialer.Dial(url, nil)
+		assert.NoError(t, err)
+		defer ws.Close()
+
+		assert.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("status")))
+
+		var result gateway.Result
+		assert.NoError(t, ws.ReadJSON(&result))
+		assert.Equal(t, gateway.Result{Command: "status", Response: "players : 0 (500 max)"}, result)
+
+		assert.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("kick bob")))
+		assert.NoError(t, ws.ReadJSON(&result))
+		assert.Equal(t, gateway.ErrNotConfirmed.Error(), result.Error)
+	})
+
+	t.Run("console confirmed", func(t *testing.T) {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/envs/rust/console?access_token=panel&confirm=true"
+
+		ws, _, err := gorilla.DefaultDialer.Dial(url, nil)
+		assert.NoError(t, err)
+		defer ws.Close()
+
+		assert.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("kick bob")))
+
+		var result gateway.Result
+		assert.NoError(t, ws.ReadJSON(&result))
+		assert.Equal(t, gateway.Result{Command: "kick bob", Error: "unknown command"}, result)
+	})
+}
+
+// droppingClient is rconcli.Client losing the connection on drop command.
+type droppingClient struct{}
+
+func (c *droppingClient) Execute(command string) (string, error) {
+	if command == "drop" {
+		return "", errConnectionLost
+	}
+
+	return command, nil
+}
+
+func (c *droppingClient) Close() error {
+	return nil
+}
+
+func TestGateway_ConnectionDrop(t *testing.T) {
+	tokens := gateway.Tokens{"admin": {Envs: []string{gateway.AllEnvs}}}
+	sessions := map[string]*rconcli.Session{"rust": {Address: "127.0.0.1:28016"}}
+
+	// The server is reachable only once, so the connection is not reopened.
+	dialed := false
+
+	gw := gateway.New(sessions, tokens, func(ses *rconcli.Session) (rconcli.Client, error) {
+		if dialed {
+			return nil, errConnectionRefused
+		}
+
+		dialed = true
+
+		return &droppingClient{}, nil
+	})
+	defer gw.Close()
+
+	results, err := gw.Execute("rust", "", false, "save", "drop", "status")
+	assert.ErrorIs(t, err, errConnectionRefused)
+	assert.Equal(t, []gateway.Result{
+		{Command: "save", Response: "save"},
+		{Command: "drop", Error: errConnectionLost.Error()},
+		{Command: "status", Error: errConnectionRefused.Error()},
+	}, results)
+}
+
+// blockingClient is rconcli.Client holding the connection until released.
+type blockingClient struct {
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (c *blockingClient) Execute(command string) (string, error) {
+	close(c.started)
+	<-c.released
+
+	return command, nil
+}
+
+func (c *blockingClient) Close() error {
+	return nil
+}
+
+func TestGateway_PoolUnavailable(t *testing.T) {
+	tokens := gateway.Tokens{"admin": {Envs: []string{gateway.AllEnvs}}}
+	sessions := map[string]*rconcli.Session{
+		"rust":    {Address: "127.0.0.1:28016"},
+		"zomboid": {Address: "127.0.0.1:16260"},
+	}
+
+	busy := &blockingClient{started: make(chan struct{}), released: make(chan struct{})}
+
+	gw := gateway.New(sessions, tokens, func(ses *rconcli.Session) (rconcli.Client, error) {
+		if ses.Address == sessions["rust"].Address {
+			return busy, nil
+		}
+
+		return &client{}, nil
+	}, pool.SetMaxConns(1))
+	defer gw.Close()
+
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	exec := func(env string) (int, string) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/envs/"+env+"/exec",
+			strings.NewReader(`{"commands":["status"]}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer admin")
+
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, strings.TrimSpace(string(body))
+	}
+
+	// The only connection is held by the request to the first environment.
+	done := make(chan int)
+
+	go func() {
+		status, _ := exec("rust")
+		done <- status
+	}()
+
+	<-busy.started
+
+	status, body := exec("zomboid")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, body, pool.ErrPoolExhausted.Error())
+
+	close(busy.released)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	t.Run("closed", func(t *testing.T) {
+		gw.Close()
+
+		status, body := exec("zomboid")
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Contains(t, body, pool.ErrPoolClosed.Error())
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/envs/zomboid/console?access_token=admin"
+
+		ws, _, err := gorilla.DefaultDialer.Dial(url, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer ws.Close()
+
+		assert.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("status")))
+
+		var result gateway.Result
+		assert.NoError(t, ws.ReadJSON(&result))
+		assert.Equal(t, pool.ErrPoolClosed.Error(), result.Error)
+
+		_, _, err = ws.ReadMessage()
+		assert.True(t, gorilla.IsCloseError(err, gorilla.CloseTryAgainLater))
+	})
+}
+
+func createFile(name, stringBody string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(stringBody)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+)
+
+// AllEnvs allows token to access all environments.
+const AllEnvs = "*"
+
+// Token contains permissions of the bearer token.
+type Token struct {
+	// Envs is the list of environments allowed for the token.
+	Envs []string `json:"envs" yaml:"envs"`
+	// Role is the name of the role from the config environment commands
+	// are filtered by. All commands are allowed if it is empty.
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+// Allowed returns true if the token can access the environment.
+func (token Token) Allowed(env string) bool {
+	for _, allowed := range token.Envs {
+		if allowed == AllEnvs || allowed == env {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tokens maps bearer tokens to their permissions.
+//
+// Example:
+// ```yaml
+// secret-panel-token:
+//
+//	envs: ["rust", "zomboid"]
+//
+// secret-moderator-token:
+//
+//	envs: ["rust"]
+//	role: "moderator"
+//
+// secret-admin-token:
+//
+//	envs: ["*"]
+//
+// ```.
+type Tokens map[string]Token
+
+// NewTokens parses tokens file.
+func NewTokens(name string) (Tokens, error) {
+	tokens := make(Tokens)
+
+	if err := codec.ReadFile(name, &tokens); err != nil {
+		return nil, fmt.Errorf("parse file: %w", err)
+	}
+
+	return tokens, nil
+}
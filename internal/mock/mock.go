@@ -0,0 +1,130 @@
+// Package mock implements fake RCON, TELNET and WebRCON servers which reply
+// to commands with canned responses from the configuration file.
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+)
+
+// DefaultResponse is returned when no rule matches received command.
+const DefaultResponse = "Unknown command"
+
+var (
+	// ErrInvalidRule is returned when rule has neither command nor regex.
+	ErrInvalidRule = errors.New("rule must contain command or regex")
+)
+
+// Rule maps a command or a regular expression to the canned reply.
+type Rule struct {
+	// Command is the exact command to reply.
+	Command string `json:"command" yaml:"command"`
+	// Regex is the regular expression to match command. Submatches can be
+	// used in Response as $1, $2 etc.
+	Regex    string `json:"regex" yaml:"regex"`
+	Response string `json:"response" yaml:"response"`
+	// Error is the error message to reply instead of Response. It is sent as
+	// the protocol specific error response.
+	Error   string        `json:"error" yaml:"error"`
+	Latency time.Duration `json:"latency" yaml:"latency"`
+
+	re *regexp.Regexp
+}
+
+// Reply is the response to send on the received command.
+type Reply struct {
+	Response string
+	Error    string
+	Latency  time.Duration
+}
+
+// Responses contains rules to reply on received commands.
+//
+// Example:
+// ```yaml
+// default: "Unknown command"
+// latency: 10ms
+// rules:
+//   - command: "players"
+//     response: "Players connected (0):"
+//   - regex: "^kickuser (.+)$"
+//     response: "User $1 kicked"
+//   - command: "quit"
+//     error: "permission denied"
+//
+// ```.
+type Responses struct {
+	Default string        `json:"default" yaml:"default"`
+	Latency time.Duration `json:"latency" yaml:"latency"`
+	Rules   []Rule        `json:"rules" yaml:"rules"`
+}
+
+// NewResponses parses responses file and compiles regular expressions. If name
+// is empty, responses with DefaultResponse for all commands is returned.
+func NewResponses(name string) (*Responses, error) {
+	responses := &Responses{Default: DefaultResponse}
+	if name == "" {
+		return responses, nil
+	}
+
+	if err := codec.ReadFile(name, responses); err != nil {
+		return nil, fmt.Errorf("parse file: %w", err)
+	}
+
+	if err := responses.Compile(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// Compile validates rules and compiles their regular expressions.
+func (responses *Responses) Compile() error {
+	for i := range responses.Rules {
+		rule := &responses.Rules[i]
+
+		switch {
+		case rule.Regex != "":
+			re, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+
+			rule.re = re
+		case rule.Command == "":
+			return fmt.Errorf("rule %d: %w", i, ErrInvalidRule)
+		}
+	}
+
+	return nil
+}
+
+// Reply returns the reply from the first rule matching the command.
+func (responses *Responses) Reply(command string) Reply {
+	for _, rule := range responses.Rules {
+		reply := Reply{Response: rule.Response, Error: rule.Error, Latency: responses.Latency}
+		if rule.Latency != 0 {
+			reply.Latency = rule.Latency
+		}
+
+		if rule.re == nil {
+			if rule.Command == command {
+				return reply
+			}
+
+			continue
+		}
+
+		if match := rule.re.FindStringSubmatchIndex(command); match != nil {
+			reply.Response = string(rule.re.ExpandString(nil, rule.Response, command, match))
+
+			return reply
+		}
+	}
+
+	return Reply{Response: responses.Default, Latency: responses.Latency}
+}
@@ -0,0 +1,188 @@
+package mock_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/telnet"
+	"github.com/stretchr/testify/assert"
+)
+
+const ResponsesYAML = `default: "No such command"
+rules:
+  - command: "players"
+    response: "Players connected (0):"
+  - regex: "^kickuser (.+)$"
+    response: "User $1 kicked"
+    latency: 10ms
+  - command: "quit"
+    error: "permission denied"
+`
+
+func TestNewResponses(t *testing.T) {
+	t.Run("empty file name", func(t *testing.T) {
+		responses, err := mock.NewResponses("")
+		assert.NoError(t, err)
+		assert.Equal(t, mock.Reply{Response: mock.DefaultResponse}, responses.Reply("players"))
+	})
+
+	t.Run("file not exists", func(t *testing.T) {
+		responses, err := mock.NewResponses("nonexist.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, responses)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		fileName := "mock-test-local.yaml"
+		createFile(fileName, "rules:\n  - response: \"nothing\"\n")
+		defer os.Remove(fileName)
+
+		responses, err := mock.NewResponses(fileName)
+		assert.ErrorIs(t, err, mock.ErrInvalidRule)
+		assert.Nil(t, responses)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		fileName := "mock-test-local.yaml"
+		createFile(fileName, ResponsesYAML)
+		defer os.Remove(fileName)
+
+		responses, err := mock.NewResponses(fileName)
+		assert.NoError(t, err)
+
+		assert.Equal(t, mock.Reply{Response: "Players connected (0):"}, responses.Reply("players"))
+		assert.Equal(t, mock.Reply{Response: "User admin kicked", Latency: 10 * time.Millisecond}, responses.Reply("kickuser admin"))
+		assert.Equal(t, mock.Reply{Error: "permission denied"}, responses.Reply("quit"))
+		assert.Equal(t, mock.Reply{Response: "No such command"}, responses.Reply("help"))
+	})
+}
+
+func TestNewServer(t *testing.T) {
+	fileName := "mock-test-local.yaml"
+	createFile(fileName, ResponsesYAML)
+	defer os.Remove(fileName)
+
+	responses, err := mock.NewResponses(fileName)
+	assert.NoError(t, err)
+
+	t.Run("unsupported protocol", func(t *testing.T) {
+		server, err := mock.NewServer("pigeon post", "127.0.0.1:0", "password", responses)
+		assert.ErrorIs(t, err, mock.ErrUnsupportedProtocol)
+		assert.Nil(t, server)
+	})
+
+	t.Run("rcon", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		conn, err := sourcercon.Dial(server.Addr(), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		result, err := conn.Execute("kickuser admin")
+		assert.NoError(t, err)
+		assert.Equal(t, "User admin kicked", result)
+
+		result, err = conn.Execute("quit")
+		assert.ErrorIs(t, err, rcon.ErrInvalidPacketID)
+		assert.Equal(t, "permission denied", result)
+	})
+
+	// Test long responses are split into packets.
+	t.Run("rcon long response", func(t *testing.T) {
+		long := &mock.Responses{Default: strings.Repeat("x", 2*sourcercon.MaxBodySize+1)}
+
+		server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", long)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		conn, err := sourcercon.Dial(server.Addr(), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		result, err := conn.Execute("cvarlist")
+		assert.NoError(t, err)
+		assert.Equal(t, long.Default, result)
+	})
+
+	// Test mirrored packets end the response.
+	t.Run("rcon mirror", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		_, err = sourcercon.Dial(server.Addr(), "wrong")
+		assert.ErrorIs(t, err, rcon.ErrAuthFailed)
+
+		conn, err := sourcercon.Dial(server.Addr(), "password", sourcercon.SetEnd(sourcercon.EndMirror))
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			result, err := conn.Execute("players")
+			assert.NoError(t, err)
+			assert.Equal(t, "Players connected (0):", result)
+		}
+	})
+
+	t.Run("telnet", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolTELNET, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		_, err = telnet.Dial(server.Addr(), "wrong")
+		assert.ErrorIs(t, err, telnet.ErrAuthFailed)
+
+		conn, err := telnet.Dial(server.Addr(), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		result, err := conn.Execute("players")
+		assert.NoError(t, err)
+		assert.Equal(t, "Players connected (0):", result)
+	})
+
+	t.Run("web", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolWebRCON, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		_, err = webrcon.Dial(server.Addr(), "wrong")
+		assert.Error(t, err)
+
+		conn, err := webrcon.Dial(server.Addr(), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		result, err := conn.Execute("players")
+		assert.NoError(t, err)
+		assert.Equal(t, "Players connected (0):", result)
+
+		result, err = conn.Execute("help")
+		assert.NoError(t, err)
+		assert.Equal(t, "No such command", result)
+
+		// Error closes the connection with the error as the close reason.
+		_, err = conn.Execute("quit")
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
+
+func createFile(name, stringBody string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(stringBody)
+
+	return err
+}
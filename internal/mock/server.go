@@ -0,0 +1,349 @@
+package mock
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/gorcon/telnet"
+	gorilla "github.com/gorilla/websocket"
+)
+
+// ErrUnsupportedProtocol is returned when mock server is requested for
+// unknown protocol type.
+var ErrUnsupportedProtocol = errors.New("unsupported protocol type")
+
+// Server is a running mock server.
+type Server interface {
+	// Addr returns the address the server is listening on.
+	Addr() string
+	// Close shuts down the server.
+	Close()
+}
+
+// NewServer starts mock server of the protocol type on the address.
+func NewServer(protocol string, address string, password string, responses *Responses) (Server, error) {
+	switch protocol {
+	case "", rconcli.ProtocolRCON, rconcli.ProtocolTELNET, rconcli.ProtocolWebRCON:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedProtocol, protocol)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	switch protocol {
+	case rconcli.ProtocolTELNET:
+		return newServerTELNET(listener, password, responses), nil
+	case rconcli.ProtocolWebRCON:
+		return newServerWebRCON(listener, password, responses), nil
+	default:
+		return newServerRCON(listener, password, responses), nil
+	}
+}
+
+// maxAuthAttempts is the number of wrong passwords TELNET server accepts
+// before closing the connection.
+const maxAuthAttempts = 3
+
+// serverTCP accepts connections on the listener and serves each of them in
+// its own goroutine.
+type serverTCP struct {
+	listener net.Listener
+	serve    func(conn net.Conn)
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// newServerTCP starts accepting connections on the listener.
+func newServerTCP(listener net.Listener, serve func(conn net.Conn)) *serverTCP {
+	s := &serverTCP{listener: listener, serve: serve, conns: make(map[net.Conn]struct{})}
+
+	s.wg.Add(1)
+
+	go s.accept()
+
+	return s
+}
+
+// Addr returns the address the server is listening on.
+func (s *serverTCP) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting connections, closes the accepted ones and waits
+// for their handlers to return.
+func (s *serverTCP) Close() {
+	_ = s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// accept accepts connections until the listener is closed.
+func (s *serverTCP) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+
+			s.serve(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+
+			_ = conn.Close()
+		}()
+	}
+}
+
+// newServerRCON starts Source RCON server on the listener.
+func newServerRCON(listener net.Listener, password string, responses *Responses) Server {
+	return newServerTCP(listener, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		authenticated := false
+
+		for {
+			request := &rcon.Packet{}
+			if _, err := request.ReadFrom(r); err != nil {
+				return
+			}
+
+			switch {
+			case request.Type == rcon.SERVERDATA_AUTH:
+				authenticated = request.Body() == password
+				if !authenticated {
+					// If authentication was failed, the ID must be assigned to -1.
+					_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, "").WriteTo(conn)
+
+					continue
+				}
+
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(conn)
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, request.ID, "").WriteTo(conn)
+			case !authenticated:
+				return
+			case request.Type == rcon.SERVERDATA_RESPONSE_VALUE:
+				// Source servers mirror SERVERDATA_RESPONSE_VALUE followed by
+				// the packet with 0x0100 body, clients use it to detect the
+				// end of the previous response.
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(conn)
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "\x00\x01\x00\x00").WriteTo(conn)
+			case request.Body() == "":
+				// Empty command is sent by clients to detect the end of
+				// the previous response.
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(conn)
+			default:
+				replyRCON(conn, request.ID, responses.Reply(request.Body()))
+			}
+		}
+	})
+}
+
+// replyRCON writes the reply to the command with the id.
+func replyRCON(conn net.Conn, id int32, reply Reply) {
+	time.Sleep(reply.Latency)
+
+	if reply.Error != "" {
+		// Client treats response for another packet id as the error.
+		_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, -1, reply.Error).WriteTo(conn)
+
+		return
+	}
+
+	// Long responses are split into multiple packets like real servers do.
+	response := reply.Response
+
+	for {
+		body := response[:min(len(response), sourcercon.MaxBodySize)]
+		response = response[len(body):]
+
+		_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, id, body).WriteTo(conn)
+
+		if response == "" {
+			return
+		}
+	}
+}
+
+// newServerTELNET starts 7 Days to Die TELNET server on the listener.
+func newServerTELNET(listener net.Listener, password string, responses *Responses) Server {
+	return newServerTCP(listener, func(conn net.Conn) {
+		scanner := bufio.NewScanner(conn)
+		w := bufio.NewWriter(conn)
+
+		if !authTELNET(scanner, w, password) {
+			return
+		}
+
+		for scanner.Scan() {
+			command := scanner.Text()
+
+			if command == telnet.DefaultExitCommand {
+				return
+			}
+
+			if command == "" {
+				continue
+			}
+
+			reply := responses.Reply(command)
+			time.Sleep(reply.Latency)
+
+			if reply.Error != "" {
+				_, _ = w.WriteString("*** ERROR: " + reply.Error + telnet.CRLF)
+			} else {
+				_, _ = w.WriteString(reply.Response + telnet.CRLF)
+			}
+
+			_ = w.Flush()
+		}
+	})
+}
+
+// authTELNET asks for the password and reports whether the client entered
+// it within maxAuthAttempts.
+func authTELNET(scanner *bufio.Scanner, w *bufio.Writer, password string) bool {
+	defer w.Flush()
+
+	_, _ = w.WriteString(telnet.ResponseEnterPassword + telnet.CRLF)
+
+	for attempt := 0; attempt < maxAuthAttempts; attempt++ {
+		_ = w.Flush()
+
+		if !scanner.Scan() {
+			return false
+		}
+
+		if scanner.Text() == password {
+			_, _ = w.WriteString(telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF)
+			_, _ = w.WriteString(telnet.ResponseWelcome + telnet.CRLF)
+
+			return true
+		}
+
+		_, _ = w.WriteString(telnet.ResponseAuthIncorrectPassword + telnet.CRLF)
+	}
+
+	_, _ = w.WriteString(telnet.ResponseAuthTooManyFails + telnet.CRLF)
+
+	return false
+}
+
+// serverWebRCON is Rust WebRCON server. Password is passed as the url path.
+type serverWebRCON struct {
+	server    *http.Server
+	listener  net.Listener
+	password  string
+	responses *Responses
+	upgrader  gorilla.Upgrader
+}
+
+// newServerWebRCON starts http server with websocket handler on the listener.
+func newServerWebRCON(listener net.Listener, password string, responses *Responses) Server {
+	s := &serverWebRCON{
+		listener:  listener,
+		password:  password,
+		responses: responses,
+		upgrader:  gorilla.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+	}
+
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: webrcon.DefaultDialTimeout}
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	return s
+}
+
+// Addr returns the address the server is listening on.
+func (s *serverWebRCON) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close shuts down the server.
+func (s *serverWebRCON) Close() {
+	_ = s.server.Close()
+}
+
+// ServeHTTP authenticates websocket connection and replies to received messages.
+func (s *serverWebRCON) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, "/") != s.password {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	for {
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request webrcon.Message
+		if err := json.Unmarshal(p, &request); err != nil {
+			return
+		}
+
+		reply := s.responses.Reply(request.Message)
+		time.Sleep(reply.Latency)
+
+		// WebRCON has no error responses, so the error closes the connection
+		// with the error as the close reason.
+		if reply.Error != "" {
+			message := gorilla.FormatCloseMessage(gorilla.CloseInternalServerErr, reply.Error)
+			_ = ws.WriteControl(gorilla.CloseMessage, message, time.Now().Add(webrcon.DefaultDialTimeout))
+
+			return
+		}
+
+		response := webrcon.Message{Message: reply.Response, Identifier: request.Identifier, Type: webrcon.TypeGeneric}
+
+		js, err := json.Marshal(response)
+		if err != nil {
+			return
+		}
+
+		if err := ws.WriteMessage(gorilla.TextMessage, js); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package pool
+
+import "time"
+
+// Settings contains option to Pool.
+type Settings struct {
+	maxConns            int
+	idleTimeout         time.Duration
+	healthCheck         HealthCheckFunc
+	healthCheckInterval time.Duration
+}
+
+// DefaultSettings provides default settings to Pool. Connections are not
+// limited, not evicted and not checked.
+var DefaultSettings = Settings{
+	maxConns:            0,
+	idleTimeout:         0,
+	healthCheck:         nil,
+	healthCheckInterval: DefaultHealthCheckInterval,
+}
+
+// Option allows to inject settings to Settings.
+type Option func(s *Settings)
+
+// SetMaxConns injects the max number of open connections. Least recently
+// used idle connection is closed when the limit is reached. Zero means
+// no limit.
+func SetMaxConns(n int) Option {
+	return func(s *Settings) {
+		s.maxConns = n
+	}
+}
+
+// SetIdleTimeout injects the duration after which unused connection is
+// closed. Zero means connections are never evicted.
+func SetIdleTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.idleTimeout = timeout
+	}
+}
+
+// SetHealthCheck injects the function checking idle connections every
+// interval. Failed connections are closed and reopened on next use.
+func SetHealthCheck(check HealthCheckFunc, interval time.Duration) Option {
+	return func(s *Settings) {
+		s.healthCheck = check
+		s.healthCheckInterval = interval
+	}
+}
@@ -0,0 +1,351 @@
+// Package pool implements a pool of connections to remote servers keyed by
+// session. Commands on one connection are executed one by one, because
+// RCON packet ids are not multiplexed and responses cannot be correlated
+// with concurrent requests.
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+)
+
+// DefaultHealthCheckInterval is the default interval between idle
+// connections checks.
+const DefaultHealthCheckInterval = 30 * time.Second
+
+var (
+	// ErrPoolClosed is returned when pool is used after Close.
+	ErrPoolClosed = errors.New("pool is closed")
+
+	// ErrPoolExhausted is returned when max connections limit is reached and
+	// all connections are busy.
+	ErrPoolExhausted = errors.New("max connections limit is reached")
+)
+
+// DialFunc creates an authorized connection to remote server for the key.
+type DialFunc func(key string) (rconcli.Client, error)
+
+// HealthCheckFunc returns error if the connection is broken.
+type HealthCheckFunc func(client rconcli.Client) error
+
+// DialError is returned when connection to remote server cannot be opened.
+type DialError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error.
+func (e *DialError) Error() string {
+	return "dial: " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
+
+// conn is the pooled connection. mu serialises access to the client.
+type conn struct {
+	mu       sync.Mutex
+	client   rconcli.Client
+	lastUsed time.Time
+}
+
+// Pool keeps connections to remote servers keyed by session.
+type Pool struct {
+	settings Settings
+	dial     DialFunc
+
+	mu     sync.Mutex
+	conns  map[string]*conn
+	closed bool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new Pool. If idle timeout or health check is set, the
+// background goroutine is started to maintain connections.
+func New(dial DialFunc, options ...Option) *Pool {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	p := &Pool{settings: settings, dial: dial, conns: make(map[string]*conn), quit: make(chan struct{})}
+
+	if interval := p.maintenanceInterval(); interval > 0 {
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+
+			p.maintain(interval)
+		}()
+	}
+
+	return p
+}
+
+// Execute executes command on the connection for the key. Connection is
+// opened if it does not exist and closed if command failed.
+func (p *Pool) Execute(key string, command string) (string, error) {
+	var response string
+
+	err := p.Do(key, func(client rconcli.Client) error {
+		var err error
+		response, err = client.Execute(command)
+
+		return err
+	})
+
+	return response, err
+}
+
+// Do calls fn with exclusive access to the connection for the key. It allows
+// to execute several commands without interleaving with other callers.
+// Connection is closed if fn returns error.
+func (p *Pool) Do(key string, fn func(client rconcli.Client) error) error {
+	c, err := p.acquire(key)
+	if err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		client, err := p.dial(key)
+		if err != nil {
+			p.remove(key, c)
+
+			return &DialError{Err: err}
+		}
+
+		c.client = client
+	}
+
+	c.lastUsed = time.Now()
+
+	if err = fn(c.client); err != nil {
+		_ = c.client.Close()
+		c.client = nil
+	}
+
+	return err
+}
+
+// Len returns the number of pooled connections.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.conns)
+}
+
+// Close closes all connections and stops background maintenance.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil
+	}
+
+	p.closed = true
+	conns := p.conns
+	p.conns = make(map[string]*conn)
+	p.mu.Unlock()
+
+	close(p.quit)
+	p.wg.Wait()
+
+	for _, c := range conns {
+		c.mu.Lock()
+		c.close()
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// acquire returns locked pooled connection for the key. Connection can be
+// evicted while waiting for the lock, in this case a new one is taken.
+func (p *Pool) acquire(key string) (*conn, error) {
+	for {
+		c, err := p.conn(key)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+
+		p.mu.Lock()
+		pooled := p.conns[key] == c
+		p.mu.Unlock()
+
+		if pooled {
+			return c, nil
+		}
+
+		c.mu.Unlock()
+	}
+}
+
+// conn returns the pooled connection for the key or creates a new one.
+// Least recently used idle connection is evicted if limit is reached.
+func (p *Pool) conn(key string) (*conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	if c, ok := p.conns[key]; ok {
+		return c, nil
+	}
+
+	if p.settings.maxConns > 0 && len(p.conns) >= p.settings.maxConns {
+		if !p.evictLocked() {
+			return nil, fmt.Errorf("%w (%d)", ErrPoolExhausted, p.settings.maxConns)
+		}
+	}
+
+	c := &conn{}
+	p.conns[key] = c
+
+	return c, nil
+}
+
+// evictLocked closes the least recently used connection which is not in
+// use. Must be called with p.mu held.
+func (p *Pool) evictLocked() bool {
+	var (
+		lruKey  string
+		lruConn *conn
+	)
+
+	for key, c := range p.conns {
+		if !c.mu.TryLock() {
+			continue
+		}
+
+		if lruConn == nil || c.lastUsed.Before(lruConn.lastUsed) {
+			if lruConn != nil {
+				lruConn.mu.Unlock()
+			}
+
+			lruKey, lruConn = key, c
+
+			continue
+		}
+
+		c.mu.Unlock()
+	}
+
+	if lruConn == nil {
+		return false
+	}
+
+	lruConn.close()
+	lruConn.mu.Unlock()
+	delete(p.conns, lruKey)
+
+	return true
+}
+
+// remove deletes the connection from the pool if it is still pooled.
+func (p *Pool) remove(key string, c *conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conns[key] == c {
+		delete(p.conns, key)
+	}
+}
+
+// maintenanceInterval returns the interval of background maintenance.
+func (p *Pool) maintenanceInterval() time.Duration {
+	interval := time.Duration(0)
+
+	if p.settings.healthCheck != nil {
+		interval = p.settings.healthCheckInterval
+	}
+
+	if timeout := p.settings.idleTimeout; timeout > 0 && (interval == 0 || timeout < interval) {
+		interval = timeout
+	}
+
+	return interval
+}
+
+// maintain evicts idle connections and checks health of the rest every
+// interval until the pool is closed.
+func (p *Pool) maintain(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastCheck := time.Now()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case now := <-ticker.C:
+			check := p.settings.healthCheck != nil && now.Sub(lastCheck) >= p.settings.healthCheckInterval
+			if check {
+				lastCheck = now
+			}
+
+			p.sweep(now, check)
+		}
+	}
+}
+
+// sweep closes idle connections and connections failed the health check.
+// Connections in use are skipped. Health checks are run without holding
+// the pool lock to not block other connections.
+func (p *Pool) sweep(now time.Time, check bool) {
+	var checked []*conn
+
+	p.mu.Lock()
+
+	for key, c := range p.conns {
+		if !c.mu.TryLock() {
+			continue
+		}
+
+		switch {
+		case p.settings.idleTimeout > 0 && now.Sub(c.lastUsed) >= p.settings.idleTimeout:
+			c.close()
+			delete(p.conns, key)
+		case check && c.client != nil:
+			checked = append(checked, c)
+
+			continue
+		}
+
+		c.mu.Unlock()
+	}
+
+	p.mu.Unlock()
+
+	for _, c := range checked {
+		if err := p.settings.healthCheck(c.client); err != nil {
+			c.close()
+		}
+
+		c.mu.Unlock()
+	}
+}
+
+// close closes the client. Must be called with c.mu held.
+func (c *conn) close() {
+	if c.client != nil {
+		_ = c.client.Close()
+		c.client = nil
+	}
+}
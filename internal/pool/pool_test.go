@@ -0,0 +1,204 @@
+package pool_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/pool"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errConnectionRefused = errors.New("connection refused")
+	errConcurrentExecute = errors.New("concurrent execute")
+	errBroken            = errors.New("broken connection")
+)
+
+// client is rconcli.Client which fails on concurrent Execute calls.
+type client struct {
+	key    string
+	busy   int32
+	closed int32
+}
+
+func (c *client) Execute(command string) (string, error) {
+	if !atomic.CompareAndSwapInt32(&c.busy, 0, 1) {
+		return "", errConcurrentExecute
+	}
+	defer atomic.StoreInt32(&c.busy, 0)
+
+	if command == "break" {
+		return "", errBroken
+	}
+
+	time.Sleep(time.Millisecond)
+
+	return c.key + ": " + command, nil
+}
+
+func (c *client) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+
+	return nil
+}
+
+// dialer creates clients and counts dials.
+type dialer struct {
+	mu      sync.Mutex
+	dials   int
+	clients []*client
+}
+
+func (d *dialer) dial(key string) (rconcli.Client, error) {
+	if key == "down" {
+		return nil, errConnectionRefused
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := &client{key: key}
+	d.dials++
+	d.clients = append(d.clients, c)
+
+	return c, nil
+}
+
+func TestPool_Execute(t *testing.T) {
+	t.Run("serialised execute", func(t *testing.T) {
+		d := &dialer{}
+
+		p := pool.New(d.dial)
+		defer p.Close()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				response, err := p.Execute("rust", "status")
+				assert.NoError(t, err)
+				assert.Equal(t, "rust: status", response)
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, 1, d.dials)
+		assert.Equal(t, 1, p.Len())
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		p := pool.New((&dialer{}).dial)
+		defer p.Close()
+
+		_, err := p.Execute("down", "status")
+
+		var dialErr *pool.DialError
+		assert.ErrorAs(t, err, &dialErr)
+		assert.ErrorIs(t, err, errConnectionRefused)
+		assert.Equal(t, 0, p.Len())
+	})
+
+	t.Run("reconnect after error", func(t *testing.T) {
+		d := &dialer{}
+
+		p := pool.New(d.dial)
+		defer p.Close()
+
+		_, err := p.Execute("rust", "break")
+		assert.ErrorIs(t, err, errBroken)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&d.clients[0].closed))
+
+		_, err = p.Execute("rust", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, d.dials)
+	})
+
+	t.Run("closed pool", func(t *testing.T) {
+		d := &dialer{}
+
+		p := pool.New(d.dial)
+
+		_, err := p.Execute("rust", "status")
+		assert.NoError(t, err)
+		assert.NoError(t, p.Close())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&d.clients[0].closed))
+
+		_, err = p.Execute("rust", "status")
+		assert.ErrorIs(t, err, pool.ErrPoolClosed)
+	})
+}
+
+func TestPool_Settings(t *testing.T) {
+	t.Run("max connections", func(t *testing.T) {
+		d := &dialer{}
+
+		p := pool.New(d.dial, pool.SetMaxConns(2))
+		defer p.Close()
+
+		for _, key := range []string{"rust", "zomboid", "rust", "7dtd"} {
+			_, err := p.Execute(key, "status")
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, 2, p.Len())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&d.clients[1].closed), "least recently used zomboid is closed")
+
+		err := p.Do("rust", func(rconcli.Client) error {
+			return p.Do("7dtd", func(rconcli.Client) error {
+				_, err := p.Execute("zomboid", "status")
+
+				return err
+			})
+		})
+		assert.ErrorIs(t, err, pool.ErrPoolExhausted)
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		d := &dialer{}
+
+		p := pool.New(d.dial, pool.SetIdleTimeout(10*time.Millisecond))
+		defer p.Close()
+
+		_, err := p.Execute("rust", "status")
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return p.Len() == 0 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&d.clients[0].closed))
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		d := &dialer{}
+
+		check := func(c rconcli.Client) error {
+			_, err := c.Execute("break")
+
+			return err
+		}
+
+		p := pool.New(d.dial, pool.SetHealthCheck(check, 10*time.Millisecond))
+		defer p.Close()
+
+		_, err := p.Execute("rust", "status")
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+
+			return atomic.LoadInt32(&d.clients[0].closed) == 1
+		}, time.Second, 5*time.Millisecond)
+
+		_, err = p.Execute("rust", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, d.dials)
+	})
+}
@@ -0,0 +1,245 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is returned when cron expression cannot be parsed.
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// maxSearchYears limits the search of the next activation for expressions
+// which never match, e.g. 30th of February.
+const maxSearchYears = 5
+
+// macros are the predefined schedules.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the allowed values of the cron expression field.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday can be set as 0 or 7.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Expression is the parsed cron expression. Each field is the bit set of
+// allowed values.
+type Expression struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// Day of month and day of week are matched with OR if both are
+	// restricted and with AND if any of them is *.
+	domStar, dowStar bool
+}
+
+// Parse parses standard 5 fields cron expression "minute hour dom month dow"
+// or 6 fields expression with leading seconds field. Fields support *, ?,
+// lists, ranges, steps, month and day of week names and macros @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight, @hourly.
+func Parse(spec string) (*Expression, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w %q: expected 5 or 6 fields", ErrInvalidCron, spec)
+	}
+
+	var (
+		expr Expression
+		err  error
+	)
+
+	bits := []*uint64{&expr.second, &expr.minute, &expr.hour, &expr.dom, &expr.month, &expr.dow}
+	specs := []field{secondField, minuteField, hourField, domField, monthField, dowField}
+
+	for i, f := range specs {
+		if *bits[i], err = f.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidCron, spec, err)
+		}
+	}
+
+	// Sunday is 0.
+	if expr.dow&(1<<7) != 0 {
+		expr.dow = expr.dow&^(1<<7) | 1
+	}
+
+	expr.domStar = isStar(fields[3])
+	expr.dowStar = isStar(fields[5])
+
+	return &expr, nil
+}
+
+// Next returns the first activation time strictly after t in the location
+// of t. Zero time is returned if the expression never matches.
+func (expr *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + maxSearchYears
+
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for expr.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !expr.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for expr.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for expr.minute&(1<<uint(t.Minute())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for expr.second&(1<<uint(t.Second())) == 0 {
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches reports whether day of month and day of week of t are allowed.
+func (expr *Expression) dayMatches(t time.Time) bool {
+	dom := expr.dom&(1<<uint(t.Day())) != 0
+	dow := expr.dow&(1<<uint(t.Weekday())) != 0
+
+	if expr.domStar || expr.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// parse returns the bit set of values allowed by the comma separated list
+// of ranges.
+func (f field) parse(spec string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(spec, ",") {
+		start, end, step, err := f.parseRange(part)
+		if err != nil {
+			return 0, err
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseRange parses *, value, start-end with optional /step.
+func (f field) parseRange(spec string) (start, end, step int, err error) {
+	rangeSpec, stepSpec, hasStep := strings.Cut(spec, "/")
+	step = 1
+
+	if hasStep {
+		if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid %s step %q", f.name, stepSpec)
+		}
+	}
+
+	switch startSpec, endSpec, isRange := strings.Cut(rangeSpec, "-"); {
+	case rangeSpec == "*" || rangeSpec == "?":
+		start, end = f.min, f.max
+	case isRange:
+		if start, err = f.value(startSpec); err != nil {
+			return 0, 0, 0, err
+		}
+
+		if end, err = f.value(endSpec); err != nil {
+			return 0, 0, 0, err
+		}
+	default:
+		if start, err = f.value(rangeSpec); err != nil {
+			return 0, 0, 0, err
+		}
+
+		// Single value with step means the range to the max value.
+		end = start
+		if hasStep {
+			end = f.max
+		}
+	}
+
+	if start > end {
+		return 0, 0, 0, fmt.Errorf("invalid %s range %q", f.name, rangeSpec)
+	}
+
+	return start, end, step, nil
+}
+
+// value parses the number or the name of the value and checks bounds.
+func (f field) value(spec string) (int, error) {
+	if value, ok := f.names[strings.ToLower(spec)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(spec)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, spec)
+	}
+
+	return value, nil
+}
+
+// isStar reports whether the field allows any value.
+func isStar(spec string) bool {
+	return spec == "*" || spec == "?"
+}
@@ -0,0 +1,61 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("invalid expressions", func(t *testing.T) {
+		for _, spec := range []string{"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+			"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "* * * foo *", "@every 1m"} {
+			_, err := schedule.Parse(spec)
+			assert.ErrorIs(t, err, schedule.ErrInvalidCron, spec)
+		}
+	})
+
+	t.Run("valid expressions", func(t *testing.T) {
+		for _, spec := range []string{"* * * * *", "*/5 * * * * *", "0 4 * * mon-fri", "0,30 8-20/2 1 jan,jul ?",
+			"@daily", "@HOURLY", "0 0 * * 7"} {
+			_, err := schedule.Parse(spec)
+			assert.NoError(t, err, spec)
+		}
+	})
+}
+
+func TestExpression_Next(t *testing.T) {
+	// Monday.
+	from := time.Date(2024, 1, 15, 10, 20, 30, 500, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 21, 0, 0, time.UTC)},
+		{"* * * * * *", time.Date(2024, 1, 15, 10, 20, 31, 0, time.UTC)},
+		{"*/15 * * * * *", time.Date(2024, 1, 15, 10, 20, 45, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2024, 1, 16, 4, 0, 0, 0, time.UTC)},
+		{"15 10 * * *", time.Date(2024, 1, 16, 10, 15, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Day of month or day of week when both are restricted.
+		{"0 0 20 * mon", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * fri", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 feb *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			expr, err := schedule.Parse(test.spec)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.want, expr.Next(from))
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package schedule
+
+import "time"
+
+// DefaultGracePeriod is the default delay after which the run is missed.
+const DefaultGracePeriod = time.Minute
+
+// Settings contains option to Scheduler.
+type Settings struct {
+	stateFile   string
+	gracePeriod time.Duration
+}
+
+// DefaultSettings provides default settings to Scheduler. State is not
+// saved, so runs missed while the scheduler was stopped are not detected.
+var DefaultSettings = Settings{
+	stateFile:   "",
+	gracePeriod: DefaultGracePeriod,
+}
+
+// Option allows to inject settings to Settings.
+type Option func(s *Settings)
+
+// SetStateFile injects the path to the file saving the times of the last
+// runs. Runs missed while the scheduler was stopped are handled by missed
+// policy of the job on start.
+func SetStateFile(name string) Option {
+	return func(s *Settings) {
+		s.stateFile = name
+	}
+}
+
+// SetGracePeriod injects the delay after which the run or the countdown
+// warning is missed, e.g. when the host was suspended.
+func SetGracePeriod(period time.Duration) Option {
+	return func(s *Settings) {
+		s.gracePeriod = period
+	}
+}
@@ -0,0 +1,204 @@
+// Package schedule implements cron expressions and the scheduler running
+// command sequences on remote servers with countdown warnings.
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+)
+
+// Missed runs policies.
+const (
+	// MissedSkip skips runs missed while the scheduler was stopped or
+	// suspended.
+	MissedSkip = "skip"
+
+	// MissedRun runs missed runs once as soon as possible.
+	MissedRun = "run"
+)
+
+// Overlapping runs policies.
+const (
+	// OverlapSkip skips the run if the previous run of the job is still
+	// running.
+	OverlapSkip = "skip"
+
+	// OverlapWait waits for the previous run of the job to finish.
+	OverlapWait = "wait"
+)
+
+var (
+	// ErrInvalidJob is returned when the job in schedule file is invalid.
+	ErrInvalidJob = errors.New("invalid job")
+)
+
+// Warning is the command sent before the run of the job.
+type Warning struct {
+	Before  time.Duration `json:"before" yaml:"before"`
+	Command string        `json:"command" yaml:"command"`
+}
+
+// Job is the sequence of commands executed on the environment by cron
+// expression.
+type Job struct {
+	Name     string   `json:"name" yaml:"name"`
+	Cron     string   `json:"cron" yaml:"cron"`
+	Env      string   `json:"env" yaml:"env"`
+	Commands []string `json:"commands" yaml:"commands"`
+	// Countdown warnings are sent before the scheduled time of the run,
+	// the commands are executed at the scheduled time.
+	Countdown []Warning `json:"countdown,omitempty" yaml:"countdown,omitempty"`
+	// Missed is the missed runs policy: skip (default) or run.
+	Missed string `json:"missed,omitempty" yaml:"missed,omitempty"`
+	// Overlap is the overlapping runs policy: skip (default) or wait.
+	Overlap string `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+
+	expr *Expression
+}
+
+// Lead returns the duration from the first countdown warning to the run.
+func (job *Job) Lead() time.Duration {
+	var lead time.Duration
+
+	for _, warning := range job.Countdown {
+		if warning.Before > lead {
+			lead = warning.Before
+		}
+	}
+
+	return lead
+}
+
+// Next returns the first scheduled time of the run strictly after t.
+func (job *Job) Next(t time.Time) time.Time {
+	return job.expr.Next(t)
+}
+
+// Schedule contains jobs to run.
+//
+// Example:
+// ```yaml
+// jobs:
+//   - name: restart
+//     cron: "0 4 * * *"
+//     env: pz
+//     countdown: [{before: 10m, command: "servermsg \"Restart in 10 minutes\""}]
+//     commands: ["save", "quit"]
+//
+// ```.
+type Schedule struct {
+	Jobs []Job `json:"jobs" yaml:"jobs"`
+}
+
+// Load reads schedule file from disk, validates jobs and parses cron
+// expressions. YAML and JSON files are supported.
+func Load(name string) (*Schedule, error) {
+	schedule := new(Schedule)
+	if err := codec.ReadFile(name, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Validate validates jobs and parses cron expressions. Countdown warnings
+// are sorted from the earliest.
+func (schedule *Schedule) Validate() error {
+	names := make(map[string]bool, len(schedule.Jobs))
+
+	for i := range schedule.Jobs {
+		job := &schedule.Jobs[i]
+
+		if job.Name == "" {
+			return fmt.Errorf("%w: name is not set for job #%d", ErrInvalidJob, i+1)
+		}
+
+		if names[job.Name] {
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidJob, job.Name)
+		}
+
+		names[job.Name] = true
+
+		expr, err := Parse(job.Cron)
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidJob, job.Name, err)
+		}
+
+		job.expr = expr
+
+		if len(job.Commands) == 0 {
+			return fmt.Errorf("%w %q: commands are not set", ErrInvalidJob, job.Name)
+		}
+
+		for _, warning := range job.Countdown {
+			if warning.Before <= 0 || warning.Command == "" {
+				return fmt.Errorf("%w %q: countdown warning must have positive before and command", ErrInvalidJob, job.Name)
+			}
+		}
+
+		sort.SliceStable(job.Countdown, func(i, j int) bool {
+			return job.Countdown[i].Before > job.Countdown[j].Before
+		})
+
+		switch job.Missed {
+		case "", MissedSkip, MissedRun:
+		default:
+			return fmt.Errorf("%w %q: unsupported missed policy %q", ErrInvalidJob, job.Name, job.Missed)
+		}
+
+		switch job.Overlap {
+		case "", OverlapSkip, OverlapWait:
+		default:
+			return fmt.Errorf("%w %q: unsupported overlap policy %q", ErrInvalidJob, job.Name, job.Overlap)
+		}
+	}
+
+	return nil
+}
+
+// State contains the scheduled times of the last runs by job names. State is
+// saved to detect runs missed while the scheduler was stopped.
+type State map[string]time.Time
+
+// LoadState reads state file from disk. Empty state is returned if the file
+// does not exist.
+func LoadState(name string) (State, error) {
+	state := make(State)
+
+	file, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	if err = json.Unmarshal(file, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Save writes state file to disk in JSON format.
+func (state State) Save(name string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const perm = 0o644
+
+	return os.WriteFile(name, data, perm)
+}
@@ -0,0 +1,107 @@
+package schedule_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+	"github.com/gorcon/rcon-cli/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+const ScheduleYAML = `jobs:
+  - name: restart
+    cron: "0 4 * * *"
+    env: pz
+    countdown:
+      - before: 1m
+        command: servermsg "Restart in 1 minute"
+      - before: 10m
+        command: servermsg "Restart in 10 minutes"
+    commands: ["save", "quit"]
+    missed: run
+  - name: announce
+    cron: "@hourly"
+    commands: ["servermsg hello"]
+    overlap: wait
+`
+
+func TestLoad(t *testing.T) {
+	fileName := "schedule-test-local.yaml"
+	defer os.Remove(fileName)
+
+	t.Run("file not exists", func(t *testing.T) {
+		jobs, err := schedule.Load("nonexist.yaml")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, jobs)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		extFileName := "schedule-test-local.txt"
+		assert.NoError(t, os.WriteFile(extFileName, []byte(ScheduleYAML), 0o644))
+		defer os.Remove(extFileName)
+
+		jobs, err := schedule.Load(extFileName)
+		assert.ErrorIs(t, err, codec.ErrUnsupportedFileExt)
+		assert.Nil(t, jobs)
+	})
+
+	t.Run("invalid jobs", func(t *testing.T) {
+		for _, body := range []string{
+			`jobs: [{cron: "@daily", commands: [save]}]`,
+			`jobs: [{name: a, cron: "@daily", commands: [save]}, {name: a, cron: "@daily", commands: [save]}]`,
+			`jobs: [{name: a, cron: "@sometimes", commands: [save]}]`,
+			`jobs: [{name: a, cron: "@daily"}]`,
+			`jobs: [{name: a, cron: "@daily", commands: [save], countdown: [{before: 1m}]}]`,
+			`jobs: [{name: a, cron: "@daily", commands: [save], missed: retry}]`,
+			`jobs: [{name: a, cron: "@daily", commands: [save], overlap: kill}]`,
+		} {
+			assert.NoError(t, os.WriteFile(fileName, []byte(body), 0o644))
+
+			jobs, err := schedule.Load(fileName)
+			assert.ErrorIs(t, err, schedule.ErrInvalidJob, body)
+			assert.Nil(t, jobs)
+		}
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(fileName, []byte(ScheduleYAML), 0o644))
+
+		jobs, err := schedule.Load(fileName)
+		assert.NoError(t, err)
+
+		if assert.Len(t, jobs.Jobs, 2) {
+			job := jobs.Jobs[0]
+			assert.Equal(t, []string{"save", "quit"}, job.Commands)
+			assert.Equal(t, 10*time.Minute, job.Lead())
+			assert.Equal(t, 10*time.Minute, job.Countdown[0].Before, "countdown is sorted from the earliest")
+
+			from := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+			assert.Equal(t, time.Date(2024, 1, 16, 4, 0, 0, 0, time.Local), job.Next(from))
+			assert.Equal(t, time.Duration(0), jobs.Jobs[1].Lead())
+		}
+	})
+}
+
+func TestState(t *testing.T) {
+	fileName := "schedule-state-test-local.json"
+	defer os.Remove(fileName)
+
+	// Test state is empty if state file does not exist.
+	t.Run("file not exists", func(t *testing.T) {
+		state, err := schedule.LoadState(fileName)
+		assert.NoError(t, err)
+		assert.Empty(t, state)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		last := time.Date(2024, 1, 15, 4, 0, 0, 0, time.UTC)
+
+		assert.NoError(t, schedule.State{"restart": last}.Save(fileName))
+
+		state, err := schedule.LoadState(fileName)
+		assert.NoError(t, err)
+		assert.True(t, last.Equal(state["restart"]))
+	})
+}
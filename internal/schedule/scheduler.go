@@ -0,0 +1,237 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// RunFunc executes commands on the remote server of the environment.
+type RunFunc func(ctx context.Context, env string, commands ...string) error
+
+// Scheduler runs jobs by their cron expressions and logs every run.
+type Scheduler struct {
+	schedule *Schedule
+	run      RunFunc
+	logger   *log.Logger
+	settings Settings
+
+	mu    sync.Mutex
+	state State
+	wg    sync.WaitGroup
+}
+
+// New creates a new Scheduler. Jobs of the schedule must be validated.
+func New(schedule *Schedule, run RunFunc, w io.Writer, options ...Option) (*Scheduler, error) {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	state := make(State)
+
+	if settings.stateFile != "" {
+		var err error
+		if state, err = LoadState(settings.stateFile); err != nil {
+			return nil, fmt.Errorf("state: %w", err)
+		}
+	}
+
+	return &Scheduler{
+		schedule: schedule,
+		run:      run,
+		logger:   log.New(w, "", log.LstdFlags),
+		settings: settings,
+		state:    state,
+	}, nil
+}
+
+// Run runs jobs until the context is done. Running jobs are interrupted and
+// Run returns when all of them are finished.
+func (s *Scheduler) Run(ctx context.Context) {
+	for i := range s.schedule.Jobs {
+		s.wg.Add(1)
+
+		go func(job *Job) {
+			defer s.wg.Done()
+
+			s.loop(ctx, job)
+		}(&s.schedule.Jobs[i])
+	}
+
+	<-ctx.Done()
+	s.wg.Wait()
+}
+
+// loop waits for the scheduled times of the job and starts runs.
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	// Busy is locked while the run of the job is in progress.
+	busy := make(chan struct{}, 1)
+
+	next := s.first(job)
+
+	for !next.IsZero() {
+		s.logf(job, "next run at %s", next.Format(time.DateTime))
+
+		if err := wait(ctx, time.Until(next.Add(-job.Lead()))); err != nil {
+			return
+		}
+
+		at, now := next, time.Now()
+
+		// Countdown warnings may be missed partially, the run is missed only if
+		// its scheduled time is passed.
+		if now.Sub(next) > s.settings.gracePeriod {
+			if job.Missed == MissedRun {
+				s.logf(job, "missed run at %s, running now", next.Format(time.DateTime))
+
+				at = now.Add(job.Lead())
+			} else {
+				s.logf(job, "missed run at %s, skipped", next.Format(time.DateTime))
+				s.save(job, now)
+
+				at = time.Time{}
+			}
+		}
+
+		if !at.IsZero() && !s.start(ctx, job, at, busy) {
+			return
+		}
+
+		if now.After(next) {
+			next = now
+		}
+
+		next = job.Next(next)
+	}
+
+	s.logf(job, "no next run")
+}
+
+// first returns the first scheduled time of the job. The time after the last
+// saved run is returned to handle runs missed while the scheduler was stopped.
+func (s *Scheduler) first(job *Job) time.Time {
+	s.mu.Lock()
+	last, ok := s.state[job.Name]
+	s.mu.Unlock()
+
+	if ok {
+		return job.Next(last)
+	}
+
+	return job.Next(time.Now())
+}
+
+// start starts the run of the job in the background respecting the overlap
+// policy of the job. Returns false if the context is done.
+func (s *Scheduler) start(ctx context.Context, job *Job, at time.Time, busy chan struct{}) bool {
+	if job.Overlap == OverlapWait {
+		select {
+		case busy <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	} else {
+		select {
+		case busy <- struct{}{}:
+		default:
+			s.logf(job, "run at %s skipped: previous run is still running", at.Format(time.DateTime))
+
+			return true
+		}
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-busy }()
+
+		s.execute(ctx, job, at)
+	}()
+
+	return true
+}
+
+// execute sends countdown warnings and executes commands of the job at the
+// scheduled time. Warnings missed by more than grace period are skipped.
+func (s *Scheduler) execute(ctx context.Context, job *Job, at time.Time) {
+	for _, warning := range job.Countdown {
+		when := at.Add(-warning.Before)
+		if time.Since(when) > s.settings.gracePeriod {
+			continue
+		}
+
+		if err := wait(ctx, time.Until(when)); err != nil {
+			return
+		}
+
+		s.logf(job, "countdown %s: %s", warning.Before, warning.Command)
+
+		if err := s.run(ctx, job.Env, warning.Command); err != nil {
+			s.logf(job, "countdown %s failed: %v", warning.Before, err)
+		}
+	}
+
+	if err := wait(ctx, time.Until(at)); err != nil {
+		return
+	}
+
+	s.logf(job, "run started")
+
+	started := time.Now()
+	err := s.run(ctx, job.Env, job.Commands...)
+
+	s.save(job, at)
+
+	if err != nil {
+		s.logf(job, "run failed after %s: %v", time.Since(started).Round(time.Millisecond), err)
+
+		return
+	}
+
+	s.logf(job, "run finished in %s", time.Since(started).Round(time.Millisecond))
+}
+
+// save saves the time of the last run of the job to the state file.
+func (s *Scheduler) save(job *Job, at time.Time) {
+	if s.settings.stateFile == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state[job.Name] = at
+
+	if err := s.state.Save(s.settings.stateFile); err != nil {
+		s.logf(job, "state: %v", err)
+	}
+}
+
+// logf writes the message about the job to the log.
+func (s *Scheduler) logf(job *Job, format string, v ...interface{}) {
+	s.logger.Printf("%s: "+format, append([]interface{}{job.Name}, v...)...)
+}
+
+// wait pauses the current goroutine for the duration or until the context
+// is done.
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package schedule_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder collects executed commands and the log of the scheduler.
+type recorder struct {
+	mu       sync.Mutex
+	commands []string
+	log      bytes.Buffer
+	delay    time.Duration
+}
+
+func (r *recorder) run(ctx context.Context, env string, commands ...string) error {
+	r.mu.Lock()
+	r.commands = append(r.commands, env+": "+strings.Join(commands, "; "))
+	r.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(r.delay):
+		return nil
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.log.Write(p)
+}
+
+func (r *recorder) result() ([]string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.commands...), r.log.String()
+}
+
+func newSchedule(t *testing.T, jobs ...schedule.Job) *schedule.Schedule {
+	t.Helper()
+
+	s := &schedule.Schedule{Jobs: jobs}
+	assert.NoError(t, s.Validate())
+
+	return s
+}
+
+func runScheduler(t *testing.T, s *schedule.Schedule, r *recorder, d time.Duration, options ...schedule.Option) {
+	t.Helper()
+
+	scheduler, err := schedule.New(s, r.run, r, options...)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	scheduler.Run(ctx)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("run every second", func(t *testing.T) {
+		r := &recorder{}
+		s := newSchedule(t, schedule.Job{Name: "save", Cron: "* * * * * *", Env: "rust", Commands: []string{"save", "status"}})
+
+		runScheduler(t, s, r, 2500*time.Millisecond)
+
+		commands, log := r.result()
+		assert.GreaterOrEqual(t, len(commands), 2)
+		assert.Equal(t, "rust: save; status", commands[0])
+		assert.Contains(t, log, "save: run started")
+		assert.Contains(t, log, "save: run finished in")
+	})
+
+	t.Run("countdown", func(t *testing.T) {
+		r := &recorder{}
+		s := newSchedule(t, schedule.Job{
+			Name:      "restart",
+			Cron:      "*/2 * * * * *",
+			Env:       "pz",
+			Commands:  []string{"quit"},
+			Countdown: []schedule.Warning{{Before: time.Second, Command: "servermsg restart in 1s"}},
+		})
+
+		runScheduler(t, s, r, 3500*time.Millisecond)
+
+		commands, log := r.result()
+		if assert.GreaterOrEqual(t, len(commands), 2) {
+			// Late warning is sent within grace period.
+			assert.Equal(t, "pz: servermsg restart in 1s", commands[0])
+			assert.Equal(t, "pz: quit", commands[1])
+		}
+
+		assert.Contains(t, log, "restart: countdown 1s: servermsg restart in 1s")
+	})
+
+	t.Run("overlap skip", func(t *testing.T) {
+		r := &recorder{delay: time.Minute}
+		s := newSchedule(t, schedule.Job{Name: "backup", Cron: "* * * * * *", Commands: []string{"backup"}})
+
+		runScheduler(t, s, r, 2500*time.Millisecond)
+
+		commands, log := r.result()
+		assert.Len(t, commands, 1)
+		assert.Contains(t, log, "backup: run at")
+		assert.Contains(t, log, "skipped: previous run is still running")
+	})
+
+	t.Run("missed skip", func(t *testing.T) {
+		stateFile := "schedule-state-test-local.json"
+		defer os.Remove(stateFile)
+
+		assert.NoError(t, schedule.State{"save": time.Now().Add(-2 * time.Hour)}.Save(stateFile))
+
+		r := &recorder{}
+		s := newSchedule(t, schedule.Job{Name: "save", Cron: "@hourly", Commands: []string{"save"}})
+
+		runScheduler(t, s, r, 200*time.Millisecond, schedule.SetStateFile(stateFile))
+
+		commands, log := r.result()
+		assert.Empty(t, commands)
+		assert.Contains(t, log, "save: missed run at")
+		assert.Contains(t, log, ", skipped")
+
+		state, err := schedule.LoadState(stateFile)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), state["save"], time.Second)
+	})
+
+	t.Run("missed run", func(t *testing.T) {
+		stateFile := "schedule-state-test-local.json"
+		defer os.Remove(stateFile)
+
+		assert.NoError(t, schedule.State{"save": time.Now().Add(-3 * time.Hour)}.Save(stateFile))
+
+		r := &recorder{}
+		s := newSchedule(t, schedule.Job{Name: "save", Cron: "@hourly", Commands: []string{"save"}, Missed: schedule.MissedRun})
+
+		runScheduler(t, s, r, 200*time.Millisecond, schedule.SetStateFile(stateFile))
+
+		commands, log := r.result()
+		assert.Equal(t, []string{": save"}, commands, "missed runs are run once")
+		assert.Contains(t, log, "save: missed run at")
+		assert.Contains(t, log, "running now")
+	})
+}
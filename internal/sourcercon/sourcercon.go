@@ -0,0 +1,329 @@
+// Package sourcercon implements Source RCON client reassembling responses
+// split by remote servers across multiple packets. The end of the response
+// is detected with one of the strategies, because the protocol does not
+// mark the last packet.
+package sourcercon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon"
+)
+
+const (
+	// DefaultDialTimeout provides default auth timeout to remote server.
+	DefaultDialTimeout = 5 * time.Second
+
+	// DefaultDeadline provides default deadline to read and write operations
+	// of commands.
+	DefaultDeadline = 5 * time.Second
+
+	// DefaultIdleTimeout provides default time to wait for the next packet
+	// of the response with EndIdle strategy.
+	DefaultIdleTimeout = 250 * time.Millisecond
+
+	// MaxBodySize is the maximum body size of response packet. Longer
+	// responses are split into multiple packets.
+	MaxBodySize = int(rcon.MaxPacketSize - rcon.MinPacketSize)
+)
+
+// End of response strategies.
+const (
+	// EndSentinel sends empty SERVERDATA_EXECCOMMAND after the command. The
+	// response ends when the response to the empty command is received.
+	EndSentinel = "sentinel"
+
+	// EndMirror sends SERVERDATA_RESPONSE_VALUE after the command. Source
+	// servers mirror it back after the last packet of the response.
+	EndMirror = "mirror"
+
+	// EndIdle reads packets until no packet is received within the idle
+	// timeout. It works with all servers at the cost of the timeout.
+	EndIdle = "idle"
+
+	// EndSingle reads only the first packet of the response. Late packets
+	// of long responses are skipped by the next command.
+	EndSingle = "single"
+)
+
+// packetTypeRust is undocumented packet type sent by Rust server before
+// the response.
+const packetTypeRust = 4
+
+// ErrUnsupportedEnd is returned when end of response strategy is unknown.
+var ErrUnsupportedEnd = errors.New("unsupported end of response strategy")
+
+// Settings contains options of Conn.
+type Settings struct {
+	dialTimeout time.Duration
+	deadline    time.Duration
+	end         string
+	idleTimeout time.Duration
+}
+
+// DefaultSettings provides default settings to Conn.
+var DefaultSettings = Settings{
+	dialTimeout: DefaultDialTimeout,
+	deadline:    DefaultDeadline,
+	end:         EndSentinel,
+	idleTimeout: DefaultIdleTimeout,
+}
+
+// Option allows to inject settings to Settings.
+type Option func(s *Settings)
+
+// SetDialTimeout injects dial timeout to Settings.
+func SetDialTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.dialTimeout = timeout
+	}
+}
+
+// SetDeadline injects read and write timeout of commands to Settings.
+func SetDeadline(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.deadline = timeout
+	}
+}
+
+// SetEnd injects end of response strategy to Settings.
+func SetEnd(end string) Option {
+	return func(s *Settings) {
+		s.end = end
+	}
+}
+
+// SetIdleTimeout injects the time to wait for the next packet with EndIdle
+// strategy to Settings.
+func SetIdleTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.idleTimeout = timeout
+	}
+}
+
+// Conn is Source RCON connection.
+type Conn struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	settings Settings
+	// id is the last request packet id.
+	id int32
+}
+
+// Dial creates a new authorized Conn tcp dialer connection.
+func Dial(address string, password string, options ...Option) (*Conn, error) {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	switch settings.end {
+	case EndSentinel, EndMirror, EndIdle, EndSingle:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedEnd, settings.end)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, settings.dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("rcon: %w", err)
+	}
+
+	client := &Conn{conn: conn, r: bufio.NewReader(conn), settings: settings}
+
+	if err = client.auth(password); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("rcon: %w", err)
+	}
+
+	return client, nil
+}
+
+// Execute sends the command to the remote server and returns the response
+// reassembled from all its packets.
+func (c *Conn) Execute(command string) (string, error) {
+	if command == "" {
+		return "", rcon.ErrCommandEmpty
+	}
+
+	if len(command) > rcon.MaxCommandLen {
+		return "", rcon.ErrCommandTooLong
+	}
+
+	id, end := c.nextID(), c.nextID()
+
+	if err := c.write(rcon.SERVERDATA_EXECCOMMAND, id, command); err != nil {
+		return "", err
+	}
+
+	switch c.settings.end {
+	case EndSentinel:
+		if err := c.write(rcon.SERVERDATA_EXECCOMMAND, end, ""); err != nil {
+			return "", err
+		}
+	case EndMirror:
+		if err := c.write(rcon.SERVERDATA_RESPONSE_VALUE, end, ""); err != nil {
+			return "", err
+		}
+	}
+
+	return c.readResponse(id, end)
+}
+
+// LocalAddr returns the local network address.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// nextID returns the next positive request packet id.
+func (c *Conn) nextID() int32 {
+	if c.id == math.MaxInt32 {
+		c.id = 0
+	}
+
+	c.id++
+
+	return c.id
+}
+
+// auth sends SERVERDATA_AUTH request and reads SERVERDATA_AUTH_RESPONSE
+// which can be preceded by an empty SERVERDATA_RESPONSE_VALUE.
+func (c *Conn) auth(password string) error {
+	if err := c.write(rcon.SERVERDATA_AUTH, rcon.SERVERDATA_AUTH_ID, password); err != nil {
+		return err
+	}
+
+	packet, err := c.read(c.settings.deadline)
+	if errors.Is(err, rcon.ErrResponseTooSmall) {
+		return rcon.ErrAuthNotRCON
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if packet.Type == rcon.SERVERDATA_RESPONSE_VALUE {
+		if packet, err = c.read(c.settings.deadline); err != nil {
+			return err
+		}
+	}
+
+	if packet.Type != rcon.SERVERDATA_AUTH_RESPONSE {
+		return rcon.ErrInvalidAuthResponse
+	}
+
+	if packet.ID == -1 {
+		return rcon.ErrAuthFailed
+	}
+
+	if packet.ID != rcon.SERVERDATA_AUTH_ID {
+		return rcon.ErrInvalidPacketID
+	}
+
+	return nil
+}
+
+// readResponse reads packets of the request id until the end of response.
+// Packets of previous requests, e.g. late packets and the second mirrored
+// packet, are skipped.
+func (c *Conn) readResponse(id int32, end int32) (string, error) {
+	var (
+		response strings.Builder
+		received bool
+		rust     bool
+	)
+
+	for {
+		timeout := c.settings.deadline
+		if c.settings.end == EndIdle && received {
+			timeout = c.settings.idleTimeout
+		}
+
+		packet, err := c.read(timeout)
+		if err != nil {
+			var netErr net.Error
+			if c.settings.end == EndIdle && received && errors.As(err, &netErr) && netErr.Timeout() {
+				return response.String(), nil
+			}
+
+			return response.String(), err
+		}
+
+		switch {
+		case packet.Type == packetTypeRust:
+			rust = true
+		case packet.ID == end && (c.settings.end == EndSentinel || c.settings.end == EndMirror):
+			return response.String(), nil
+		case packet.ID == id, packet.ID == -1 && rust:
+			// Rust server responds with console message of id -1 after
+			// packet of type 4 to some commands, e.g. say.
+			response.WriteString(packet.Body())
+			received = true
+
+			if c.settings.end == EndSingle {
+				return response.String(), nil
+			}
+		case packet.ID >= 0 && packet.ID < id:
+		default:
+			return packet.Body(), rcon.ErrInvalidPacketID
+		}
+	}
+}
+
+// write creates packet and writes it to established tcp conn.
+func (c *Conn) write(packetType int32, packetID int32, body string) error {
+	if c.settings.deadline != 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.settings.deadline)); err != nil {
+			return fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	_, err := rcon.NewPacket(packetType, packetID, body).WriteTo(c.conn)
+
+	return err
+}
+
+// read reads the packet. The timeout limits waiting for the first bytes of
+// the packet, the rest of it is read within the deadline, so the stream is
+// not broken by the idle timeout in the middle of the packet.
+func (c *Conn) read(timeout time.Duration) (*rcon.Packet, error) {
+	if timeout != 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, fmt.Errorf("rcon: %w", err)
+		}
+
+		if _, err := c.r.Peek(1); err != nil {
+			return nil, fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	if c.settings.deadline != 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.settings.deadline)); err != nil {
+			return nil, fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	packet := &rcon.Packet{}
+	if _, err := packet.ReadFrom(c.r); err != nil {
+		return packet, err
+	}
+
+	return packet, nil
+}
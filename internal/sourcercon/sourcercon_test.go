@@ -0,0 +1,243 @@
+package sourcercon_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+	"github.com/stretchr/testify/assert"
+)
+
+// longResponse is split into three packets.
+var longResponse = strings.Repeat("sv_cheats 0\n", 2*sourcercon.MaxBodySize/12+100)
+
+// newServer starts Source RCON server splitting long responses into packets.
+// Source servers mirror SERVERDATA_RESPONSE_VALUE followed by the packet
+// with 0x01 body, other servers respond with an error message to it. Packets
+// of the response are sent with the delay between them.
+func newServer(t *testing.T, mirror bool, delay time.Duration) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serve(conn, mirror, delay)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serve(conn net.Conn, mirror bool, delay time.Duration) {
+	defer conn.Close()
+
+	for {
+		request := &rcon.Packet{}
+		if _, err := request.ReadFrom(conn); err != nil {
+			return
+		}
+
+		switch request.Type {
+		case rcon.SERVERDATA_AUTH:
+			if request.Body() != "password" {
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, "").WriteTo(conn)
+
+				continue
+			}
+
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(conn)
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, request.ID, "").WriteTo(conn)
+		case rcon.SERVERDATA_RESPONSE_VALUE:
+			if !mirror {
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "Unknown request 0").WriteTo(conn)
+
+				continue
+			}
+
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(conn)
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "\x00\x01\x00\x00").WriteTo(conn)
+		case rcon.SERVERDATA_EXECCOMMAND:
+			switch request.Body() {
+			case "cvarlist":
+				for response := longResponse; response != ""; {
+					body := response[:min(len(response), sourcercon.MaxBodySize)]
+					response = response[len(body):]
+
+					_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, body).WriteTo(conn)
+
+					time.Sleep(delay)
+				}
+			case "quit":
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, -1, "permission denied").WriteTo(conn)
+			case "":
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "Unknown command").WriteTo(conn)
+			default:
+				_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "hostname: test").WriteTo(conn)
+			}
+		}
+	}
+}
+
+func TestConn_Execute(t *testing.T) {
+	tests := []struct {
+		end    string
+		mirror bool
+	}{
+		{sourcercon.EndSentinel, false},
+		{sourcercon.EndMirror, true},
+		{sourcercon.EndMirror, false},
+		{sourcercon.EndIdle, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		name := tt.end
+		if tt.end == sourcercon.EndMirror && !tt.mirror {
+			name += " not supported"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			address := newServer(t, tt.mirror, 10*time.Millisecond)
+
+			conn, err := sourcercon.Dial(address, "password", sourcercon.SetEnd(tt.end),
+				sourcercon.SetIdleTimeout(100*time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			// Responses of the next commands are not mixed with packets of
+			// the previous ones.
+			for i := 0; i < 2; i++ {
+				response, err := conn.Execute("cvarlist")
+				assert.NoError(t, err)
+				assert.Equal(t, longResponse, response)
+
+				response, err = conn.Execute("status")
+				assert.NoError(t, err)
+				assert.Equal(t, "hostname: test", response)
+			}
+		})
+	}
+
+	// Test single packet is read and late packets are skipped.
+	t.Run(sourcercon.EndSingle, func(t *testing.T) {
+		address := newServer(t, false, 0)
+
+		conn, err := sourcercon.Dial(address, "password", sourcercon.SetEnd(sourcercon.EndSingle))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		response, err := conn.Execute("cvarlist")
+		assert.NoError(t, err)
+		assert.Equal(t, longResponse[:sourcercon.MaxBodySize], response)
+
+		response, err = conn.Execute("status")
+		assert.NoError(t, err)
+		assert.Equal(t, "hostname: test", response)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		conn, err := sourcercon.Dial(newServer(t, true, 0), "password")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		response, err := conn.Execute("quit")
+		assert.ErrorIs(t, err, rcon.ErrInvalidPacketID)
+		assert.Equal(t, "permission denied", response)
+
+		response, err = conn.Execute("status")
+		assert.NoError(t, err)
+		assert.Equal(t, "hostname: test", response)
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		conn, err := sourcercon.Dial(newServer(t, true, 0), "password")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		_, err = conn.Execute("")
+		assert.ErrorIs(t, err, rcon.ErrCommandEmpty)
+
+		_, err = conn.Execute(strings.Repeat("a", rcon.MaxCommandLen+1))
+		assert.ErrorIs(t, err, rcon.ErrCommandTooLong)
+	})
+}
+
+func TestDial(t *testing.T) {
+	address := newServer(t, true, 0)
+
+	t.Run("wrong password", func(t *testing.T) {
+		conn, err := sourcercon.Dial(address, "wrong")
+		assert.ErrorIs(t, err, rcon.ErrAuthFailed)
+		assert.Nil(t, conn)
+	})
+
+	t.Run("unsupported end", func(t *testing.T) {
+		conn, err := sourcercon.Dial(address, "password", sourcercon.SetEnd("eof"))
+		assert.ErrorIs(t, err, sourcercon.ErrUnsupportedEnd)
+		assert.Nil(t, conn)
+	})
+
+	t.Run("not rcon server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			// Size of the response is smaller than the packet header.
+			_, _ = conn.Write([]byte{2, 0, 0, 0, 0, 0})
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+
+		conn, err := sourcercon.Dial(listener.Addr().String(), "password")
+		assert.ErrorIs(t, err, rcon.ErrAuthNotRCON)
+		assert.Nil(t, conn)
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		closed := listener.Addr().String()
+		_ = listener.Close()
+
+		_, err = sourcercon.Dial(closed, "password")
+
+		var netErr *net.OpError
+		assert.True(t, errors.As(err, &netErr))
+	})
+}
@@ -0,0 +1,371 @@
+// Package table parses whitespace aligned tables from command responses and
+// renders them as aligned columns, csv or tsv.
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Ellipsis replaces the end of truncated cells.
+const Ellipsis = "…"
+
+// Errors.
+var (
+	// ErrNoTable is returned when response does not contain a table.
+	ErrNoTable = errors.New("response is not a table")
+
+	// ErrUnknownColumn is returned when selected or sorted column is not
+	// in the table header.
+	ErrUnknownColumn = errors.New("unknown column")
+)
+
+// headerWord matches cells of detected header line.
+var headerWord = regexp.MustCompile(`^[#A-Za-z_][\w.\-/%#]*$`)
+
+// Table is the table parsed from command response. Lines before and after
+// the table are kept to print them around the table.
+type Table struct {
+	Preamble []string
+	Header   []string
+	Rows     [][]string
+	Footer   []string
+}
+
+// Parse finds the table in the response. Header is the first line matching
+// the header regex or, if it is nil, the first line of two or more words
+// followed by rows. Rows are lines after the header until an empty or single
+// word line. Double quoted cells may contain spaces. Cells of rows with
+// missing cells are aligned to the positions of header columns.
+func Parse(response string, header *regexp.Regexp) (*Table, error) {
+	lines := strings.Split(strings.ReplaceAll(response, "\r\n", "\n"), "\n")
+
+	for i, line := range lines {
+		if header != nil && !header.MatchString(line) {
+			continue
+		}
+
+		names := split(line)
+		if header == nil && !isHeader(names) {
+			continue
+		}
+
+		table := &Table{Preamble: lines[:i], Header: names}
+
+		end := i + 1
+		for ; end < len(lines); end++ {
+			if len(split(lines[end])) < 2 {
+				break
+			}
+
+			table.Rows = append(table.Rows, row(lines[end], line, len(names)))
+		}
+
+		// Detected table must have rows not to render any two words.
+		if header == nil && len(table.Rows) == 0 {
+			continue
+		}
+
+		table.Footer = lines[end:]
+
+		return table, nil
+	}
+
+	return nil, ErrNoTable
+}
+
+// isHeader reports whether cells look like column names.
+func isHeader(cells []string) bool {
+	if len(cells) < 2 {
+		return false
+	}
+
+	for _, cell := range cells {
+		if !headerWord.MatchString(cell) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// split splits the line by whitespaces. Double quotes are removed from
+// quoted cells.
+func split(line string) []string {
+	cells, _ := tokenize(line)
+
+	return cells
+}
+
+// tokenize splits the line by whitespaces and returns cells with their byte
+// positions in the line.
+func tokenize(line string) ([]string, []int) {
+	var (
+		cells  []string
+		starts []int
+		cell   strings.Builder
+		quoted bool
+		inCell bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+
+		if !inCell && ch != ' ' && ch != '\t' {
+			starts = append(starts, i)
+		}
+
+		switch {
+		case quoted && ch == '\\' && i+1 < len(line):
+			i++
+			cell.WriteByte(line[i])
+		case ch == '"':
+			quoted = !quoted
+			inCell = true
+		case !quoted && (ch == ' ' || ch == '\t'):
+			if inCell {
+				cells = append(cells, cell.String())
+				cell.Reset()
+				inCell = false
+			}
+		default:
+			cell.WriteByte(ch)
+			inCell = true
+		}
+	}
+
+	if inCell {
+		cells = append(cells, cell.String())
+	}
+
+	return cells, starts
+}
+
+// row splits the line to n cells. Extra cells are joined to the last one,
+// missing cells are found by aligning cells to header columns.
+func row(line, header string, n int) []string {
+	cells, starts := tokenize(line)
+
+	switch {
+	case len(cells) == n:
+		return cells
+	case len(cells) > n:
+		return append(cells[:n-1], strings.Join(cells[n-1:], " "))
+	}
+
+	_, columns := tokenize(header)
+
+	result := make([]string, n)
+	for i, column := range align(starts, columns) {
+		result[column] = cells[i]
+	}
+
+	return result
+}
+
+// align assigns cells to columns in order minimizing the total distance
+// between cell and column positions. It returns the column of each cell.
+func align(cells, columns []int) []int {
+	n, m := len(cells), len(columns)
+
+	// cost[i][j] is the minimal distance of first i cells placed in first
+	// j columns.
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+
+		for j := range cost[i] {
+			if i > j {
+				cost[i][j] = math.MaxInt / 2
+			}
+		}
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := i; j <= m; j++ {
+			placed := cost[i-1][j-1] + abs(cells[i-1]-columns[j-1])
+			cost[i][j] = min(placed, cost[i][j-1])
+		}
+	}
+
+	result := make([]int, n)
+
+	for i, j := n, m; i > 0; j-- {
+		if cost[i][j] != cost[i][j-1] || j == i {
+			result[i-1] = j - 1
+			i--
+		}
+	}
+
+	return result
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// column returns the index of the column by case insensitive name.
+func (table *Table) column(name string) (int, error) {
+	for i, header := range table.Header {
+		if strings.EqualFold(header, name) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w %q: columns are %s", ErrUnknownColumn, name, strings.Join(table.Header, ", "))
+}
+
+// Select keeps only the columns in the given order.
+func (table *Table) Select(names ...string) error {
+	indexes := make([]int, 0, len(names))
+
+	for _, name := range names {
+		i, err := table.column(name)
+		if err != nil {
+			return err
+		}
+
+		indexes = append(indexes, i)
+	}
+
+	pick := func(cells []string) []string {
+		result := make([]string, len(indexes))
+
+		for i, index := range indexes {
+			if index < len(cells) {
+				result[i] = cells[index]
+			}
+		}
+
+		return result
+	}
+
+	table.Header = pick(table.Header)
+
+	for i := range table.Rows {
+		table.Rows[i] = pick(table.Rows[i])
+	}
+
+	return nil
+}
+
+// Sort sorts rows by the column. Name prefixed with minus sorts in
+// descending order. Numbers are compared as numbers, other cells as
+// strings.
+func (table *Table) Sort(name string) error {
+	name, desc := strings.CutPrefix(name, "-")
+
+	i, err := table.column(name)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(table.Rows, func(a, b int) bool {
+		if desc {
+			return less(table.Rows[b][i], table.Rows[a][i])
+		}
+
+		return less(table.Rows[a][i], table.Rows[b][i])
+	})
+
+	return nil
+}
+
+// less compares cells as numbers if both are numbers.
+func less(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+
+	if errX == nil && errY == nil {
+		return x < y
+	}
+
+	return a < b
+}
+
+// Write prints preamble, the table as aligned columns and footer. Cells
+// longer than width are truncated, zero width does not truncate.
+func (table *Table) Write(w io.Writer, width int) error {
+	for _, line := range table.Preamble {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+
+	for _, cells := range append([][]string{table.Header}, table.Rows...) {
+		truncated := make([]string, len(cells))
+		for i, cell := range cells {
+			truncated[i] = truncate(cell, width)
+		}
+
+		_, _ = fmt.Fprintln(tw, strings.Join(truncated, "\t"))
+	}
+
+	_ = tw.Flush()
+
+	// Padding of empty last cells is trimmed.
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line == "" {
+			continue
+		}
+
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " \n")); err != nil {
+			return err
+		}
+	}
+
+	footer := strings.TrimRight(strings.Join(table.Footer, "\n"), "\n")
+	if footer == "" {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(w, footer)
+
+	return err
+}
+
+// WriteCSV prints header and rows separated by comma. Preamble and footer
+// are not printed.
+func (table *Table) WriteCSV(w io.Writer, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	if err := writer.Write(table.Header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(table.Rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// truncate shortens the cell to width runes with ellipsis.
+func truncate(cell string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(cell) <= width {
+		return cell
+	}
+
+	runes := []rune(cell)
+
+	return string(runes[:width-1]) + Ellipsis
+}
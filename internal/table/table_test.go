@@ -0,0 +1,125 @@
+package table_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/table"
+	"github.com/stretchr/testify/assert"
+)
+
+const rustStatus = `hostname: Rust Server [DOCKER]
+players : 3 (500 max) (0 queued) (0 joining)
+
+id                name           ping connected addr                owner violation kicks
+76561198000000001 "Player"       52   102.45s   203.0.113.5:56789         0.0       0
+76561198000000002 "Night Owl"    120  3600s     203.0.113.6:1234          0.0       0
+76561198000000003 "Averyveryverylongname" 8 5s  203.0.113.7:1234          0.0       0`
+
+func TestParse(t *testing.T) {
+	t.Run("rust status", func(t *testing.T) {
+		tbl, err := table.Parse(rustStatus, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hostname: Rust Server [DOCKER]", "players : 3 (500 max) (0 queued) (0 joining)", ""}, tbl.Preamble)
+		assert.Equal(t, []string{"id", "name", "ping", "connected", "addr", "owner", "violation", "kicks"}, tbl.Header)
+		assert.Len(t, tbl.Rows, 3)
+		assert.Equal(t, []string{"76561198000000002", "Night Owl", "120", "3600s", "203.0.113.6:1234", "", "0.0", "0"}, tbl.Rows[1])
+		assert.Empty(t, tbl.Footer)
+	})
+
+	t.Run("extra cells", func(t *testing.T) {
+		tbl, err := table.Parse("name state\nbob active since yesterday\n#end", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"bob", "active since yesterday"}}, tbl.Rows)
+		assert.Equal(t, []string{"#end"}, tbl.Footer)
+	})
+
+	// Test plain responses are not detected as tables.
+	t.Run("no table", func(t *testing.T) {
+		for _, response := range []string{
+			"",
+			"unknown command",
+			"Players connected (2):\n-admin\n-bob",
+			"There are 2 of a max of 20 players online: Steve, Alex",
+		} {
+			_, err := table.Parse(response, nil)
+			assert.ErrorIs(t, err, table.ErrNoTable, response)
+		}
+	})
+
+	// Test header regex allows tables without rows.
+	t.Run("header regex", func(t *testing.T) {
+		tbl, err := table.Parse("players : 0\nid name ping", regexp.MustCompile(`^id `))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "ping"}, tbl.Header)
+		assert.Empty(t, tbl.Rows)
+	})
+}
+
+func TestTable_Select(t *testing.T) {
+	tbl, err := table.Parse(rustStatus, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tbl.Select("Name", "ping"))
+	assert.Equal(t, []string{"name", "ping"}, tbl.Header)
+	assert.Equal(t, []string{"Player", "52"}, tbl.Rows[0])
+
+	err = tbl.Select("kicks")
+	assert.ErrorIs(t, err, table.ErrUnknownColumn)
+	assert.EqualError(t, err, `unknown column "kicks": columns are name, ping`)
+}
+
+func TestTable_Sort(t *testing.T) {
+	tbl, err := table.Parse(rustStatus, nil)
+	assert.NoError(t, err)
+
+	names := func() []string {
+		var result []string
+		for _, row := range tbl.Rows {
+			result = append(result, row[1])
+		}
+
+		return result
+	}
+
+	// Test numbers are not compared as strings.
+	assert.NoError(t, tbl.Sort("ping"))
+	assert.Equal(t, []string{"Averyveryverylongname", "Player", "Night Owl"}, names())
+
+	assert.NoError(t, tbl.Sort("-name"))
+	assert.Equal(t, []string{"Player", "Night Owl", "Averyveryverylongname"}, names())
+
+	assert.ErrorIs(t, tbl.Sort("score"), table.ErrUnknownColumn)
+}
+
+func TestTable_Write(t *testing.T) {
+	tbl, err := table.Parse(rustStatus, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, tbl.Select("name", "ping", "addr"))
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, tbl.Write(w, 10))
+	assert.Equal(t, `hostname: Rust Server [DOCKER]
+players : 3 (500 max) (0 queued) (0 joining)
+
+name        ping  addr
+Player      52    203.0.113…
+Night Owl   120   203.0.113…
+Averyvery…  8     203.0.113…
+`, w.String())
+}
+
+func TestTable_WriteCSV(t *testing.T) {
+	tbl, err := table.Parse(rustStatus, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, tbl.Select("name", "ping"))
+
+	w := &bytes.Buffer{}
+	assert.NoError(t, tbl.WriteCSV(w, ','))
+	assert.Equal(t, "name,ping\nPlayer,52\nNight Owl,120\nAveryveryverylongname,8\n", w.String())
+
+	w.Reset()
+	assert.NoError(t, tbl.WriteCSV(w, '\t'))
+	assert.Equal(t, "name\tping\nPlayer\t52\nNight Owl\t120\nAveryveryverylongname\t8\n", w.String())
+}
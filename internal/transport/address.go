@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress is returned when address is not host, host:port,
+	// IPv6 or [IPv6]:port.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrNoSRVRecords is returned when SRV lookup returns no targets.
+	ErrNoSRVRecords = errors.New("no srv records")
+)
+
+// Resolver looks up SRV records. net.Resolver implements it.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// JoinPort returns the address with the port appended if it has no port.
+// Address can be host, host:port, IPv6 with or without brackets and
+// [IPv6]:port. URLs, e.g. ws://host/path, are returned as is.
+func JoinPort(address string, port int) (string, error) {
+	if strings.Contains(address, "://") {
+		return address, nil
+	}
+
+	if host, p, err := net.SplitHostPort(address); err == nil {
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return "", fmt.Errorf("%w %q: port must be 1-65535", ErrInvalidAddress, address)
+		}
+
+		return net.JoinHostPort(host, p), nil
+	}
+
+	host := address
+
+	switch {
+	case strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]"):
+		host = address[1 : len(address)-1]
+		if net.ParseIP(host) == nil {
+			return "", fmt.Errorf("%w %q", ErrInvalidAddress, address)
+		}
+	case strings.Contains(address, ":") && net.ParseIP(address) == nil:
+		return "", fmt.Errorf("%w %q: enclose IPv6 address in brackets", ErrInvalidAddress, address)
+	case address == "":
+		return "", fmt.Errorf("%w: empty host", ErrInvalidAddress)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// LookupSRV returns host:port targets of SRV records of the name, e.g.
+// _rcon._tcp.example.com, ordered by priority and randomized by weight.
+func LookupSRV(ctx context.Context, resolver Resolver, name string) ([]string, error) {
+	_, records, err := resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, fmt.Errorf("srv: %w", err)
+	}
+
+	targets := make([]string, 0, len(records))
+
+	for _, record := range records {
+		// Target "." means the service is not available.
+		if host := strings.TrimSuffix(record.Target, "."); host != "" {
+			targets = append(targets, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("srv: %w for %s", ErrNoSRVRecords, name)
+	}
+
+	return targets, nil
+}
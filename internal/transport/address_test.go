@@ -0,0 +1,80 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    string
+		err     error
+	}{
+		{"host", "rcon.example.com", "rcon.example.com:27015", nil},
+		{"host and port", "rcon.example.com:28016", "rcon.example.com:28016", nil},
+		{"ipv4", "127.0.0.1", "127.0.0.1:27015", nil},
+		{"ipv6", "::1", "[::1]:27015", nil},
+		{"bracketed ipv6", "[2001:db8::1]", "[2001:db8::1]:27015", nil},
+		{"bracketed ipv6 and port", "[2001:db8::1]:25575", "[2001:db8::1]:25575", nil},
+		{"url", "wss://rcon.example.com/rcon", "wss://rcon.example.com/rcon", nil},
+		{"ambiguous ipv6", "2001:db8::1:99999", "", transport.ErrInvalidAddress},
+		{"invalid bracketed ipv6", "[rcon.example.com]", "", transport.ErrInvalidAddress},
+		{"port out of range", "127.0.0.1:65536", "", transport.ErrInvalidAddress},
+		{"invalid port", "127.0.0.1:rcon", "", transport.ErrInvalidAddress},
+		{"empty", "", "", transport.ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transport.JoinPort(tt.address, 27015)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// resolver returns the records or the error for any name.
+type resolver struct {
+	records []*net.SRV
+	err     error
+}
+
+func (r resolver) LookupSRV(context.Context, string, string, string) (string, []*net.SRV, error) {
+	return "", r.records, r.err
+}
+
+func TestLookupSRV(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		records := []*net.SRV{
+			{Target: "rcon1.example.com.", Port: 27015},
+			{Target: "2001:db8::1", Port: 27016},
+		}
+
+		targets, err := transport.LookupSRV(context.Background(), resolver{records: records}, "_rcon._tcp.example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rcon1.example.com:27015", "[2001:db8::1]:27016"}, targets)
+	})
+
+	t.Run("service not available", func(t *testing.T) {
+		records := []*net.SRV{{Target: ".", Port: 0}}
+
+		_, err := transport.LookupSRV(context.Background(), resolver{records: records}, "_rcon._tcp.example.com")
+		assert.ErrorIs(t, err, transport.ErrNoSRVRecords)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		lookupErr := errors.New("no such host")
+
+		_, err := transport.LookupSRV(context.Background(), resolver{err: lookupErr}, "_rcon._tcp.example.com")
+		assert.ErrorIs(t, err, lookupErr)
+	})
+}
@@ -0,0 +1,213 @@
+// Package transport establishes connections to remote servers through TLS
+// and forwards local connections of protocol clients which dial plain TCP.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// ErrInvalidCA is returned when CA file does not contain certificates.
+var ErrInvalidCA = errors.New("no certificates found in ca file")
+
+// DialFunc connects to the address on the named network.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Direct dials the address without proxies.
+func Direct(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+
+	return dialer.DialContext(ctx, network, address)
+}
+
+// TLSOptions contains options of TLS connection.
+type TLSOptions struct {
+	// CAFile is the PEM file of certificate authorities verifying the server.
+	// System pool is used if it is empty.
+	CAFile string
+	// CertFile and KeyFile are PEM files of the client certificate.
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+	// ServerName is the SNI and verified name of the server. Host of
+	// the address is used if it is empty.
+	ServerName string
+}
+
+// TLSConfig returns TLS config with certificates loaded from files.
+func TLSConfig(opts TLSOptions, address string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec // Explicitly requested by the user.
+	}
+
+	if cfg.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		cfg.ServerName = host
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: %w: %s", ErrInvalidCA, opts.CAFile)
+		}
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// WithTLS returns DialFunc wrapping connections of dial in TLS. Handshake
+// is completed before the connection is returned.
+func WithTLS(dial DialFunc, cfg *tls.Config) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, cfg)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// Forwarder accepts a local connection and forwards it to the remote
+// address through DialFunc. It allows protocol clients dialing plain TCP
+// to connect through TLS and tunnels. Only the first local connection is
+// accepted, so other local processes cannot use the remote connection and
+// the client certificate or tunnel behind it.
+type Forwarder struct {
+	listener net.Listener
+	remote   net.Conn
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Forward dials the remote address and starts Forwarder listening on random
+// local port. The remote connection is dialed before Forward returns to
+// report dial errors to the caller instead of closing local connection.
+func Forward(ctx context.Context, dial DialFunc, address string) (*Forwarder, error) {
+	remote, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = remote.Close()
+
+		return nil, fmt.Errorf("forward: %w", err)
+	}
+
+	forwarderCtx, cancel := context.WithCancel(context.Background())
+
+	forwarder := &Forwarder{
+		listener: listener,
+		remote:   remote,
+		ctx:      forwarderCtx,
+		cancel:   cancel,
+	}
+
+	forwarder.wg.Add(1)
+
+	go forwarder.serve()
+
+	return forwarder, nil
+}
+
+// Addr returns the local address to connect to.
+func (f *Forwarder) Addr() string {
+	return f.listener.Addr().String()
+}
+
+// Close stops listening and closes forwarded connections.
+func (f *Forwarder) Close() error {
+	f.cancel()
+	err := f.listener.Close()
+	f.wg.Wait()
+
+	_ = f.remote.Close()
+
+	if errors.Is(err, net.ErrClosed) {
+		// The listener is closed after the first connection.
+		return nil
+	}
+
+	return err
+}
+
+// serve accepts the first local connection, stops listening and forwards it.
+func (f *Forwarder) serve() {
+	defer f.wg.Done()
+
+	local, err := f.listener.Accept()
+
+	_ = f.listener.Close()
+
+	if err != nil {
+		return
+	}
+
+	f.forward(local)
+}
+
+// forward copies data in both directions until one side closes
+// the connection or the forwarder is closed.
+func (f *Forwarder) forward(local net.Conn) {
+	defer local.Close()
+	defer f.remote.Close()
+
+	stop := context.AfterFunc(f.ctx, func() {
+		_ = local.Close()
+		_ = f.remote.Close()
+	})
+	defer stop()
+
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(f.remote, local)
+		done <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(local, f.remote)
+		done <- struct{}{}
+	}()
+
+	<-done
+}
@@ -0,0 +1,151 @@
+package transport_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// newEchoServer starts TLS server echoing lines back with the certificate
+// of httptest and returns its address and CA file with the certificate.
+func newEchoServer(t *testing.T) (string, string) {
+	t.Helper()
+
+	certs := httptest.NewTLSServer(nil)
+	t.Cleanup(certs.Close)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certs.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = io.WriteString(conn, scanner.Text()+"\n")
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), writeCA(t, certs.Certificate())
+}
+
+func writeCA(t *testing.T, cert *x509.Certificate) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return name
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Run("server name from address", func(t *testing.T) {
+		cfg, err := transport.TLSConfig(transport.TLSOptions{}, "rcon.example.com:27015")
+		assert.NoError(t, err)
+		assert.Equal(t, "rcon.example.com", cfg.ServerName)
+		assert.Nil(t, cfg.RootCAs)
+
+		cfg, err = transport.TLSConfig(transport.TLSOptions{ServerName: "proxy.example.com"}, "127.0.0.1:27015")
+		assert.NoError(t, err)
+		assert.Equal(t, "proxy.example.com", cfg.ServerName)
+	})
+
+	t.Run("invalid ca file", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "ca.pem")
+		assert.NoError(t, os.WriteFile(name, []byte("not a certificate"), 0o600))
+
+		_, err := transport.TLSConfig(transport.TLSOptions{CAFile: name}, "127.0.0.1:27015")
+		assert.ErrorIs(t, err, transport.ErrInvalidCA)
+	})
+
+	t.Run("missing client certificate", func(t *testing.T) {
+		_, err := transport.TLSConfig(transport.TLSOptions{CertFile: "client.pem", KeyFile: "client.key"}, "")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestForward(t *testing.T) {
+	address, ca := newEchoServer(t)
+
+	t.Run("success", func(t *testing.T) {
+		cfg, err := transport.TLSConfig(transport.TLSOptions{CAFile: ca, ServerName: "example.com"}, address)
+		assert.NoError(t, err)
+
+		forwarder, err := transport.Forward(context.Background(), transport.WithTLS(transport.Direct, cfg), address)
+		assert.NoError(t, err)
+		defer forwarder.Close()
+
+		conn, err := net.Dial("tcp", forwarder.Addr())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = io.WriteString(conn, "status\n")
+		assert.NoError(t, err)
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, "status\n", line)
+
+		// Other local processes cannot connect through the forwarder.
+		_, err = net.Dial("tcp", forwarder.Addr())
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown authority", func(t *testing.T) {
+		cfg, err := transport.TLSConfig(transport.TLSOptions{}, address)
+		assert.NoError(t, err)
+
+		_, err = transport.Forward(context.Background(), transport.WithTLS(transport.Direct, cfg), address)
+		var unknown x509.UnknownAuthorityError
+		assert.ErrorAs(t, err, &unknown)
+	})
+
+	t.Run("close stops forwarding", func(t *testing.T) {
+		cfg, err := transport.TLSConfig(transport.TLSOptions{InsecureSkipVerify: true}, address)
+		assert.NoError(t, err)
+
+		forwarder, err := transport.Forward(context.Background(), transport.WithTLS(transport.Direct, cfg), address)
+		assert.NoError(t, err)
+
+		conn, err := net.Dial("tcp", forwarder.Addr())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = io.WriteString(conn, "status\n")
+		assert.NoError(t, err)
+
+		assert.NoError(t, forwarder.Close())
+
+		// Read ends with EOF or reset when the local connection is closed.
+		_, _ = io.ReadAll(conn)
+
+		_, err = net.Dial("tcp", forwarder.Addr())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,427 @@
+// Package transporttest contains in-process SSH bastion and SOCKS5 proxy
+// for testing connections through tunnels.
+package transporttest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// BastionUser is the user name accepted by Bastion.
+const BastionUser = "rcon"
+
+// server accepts connections and tracks handlers to wait for them on close.
+type server struct {
+	listener    net.Listener
+	connections atomic.Int32
+	wg          sync.WaitGroup
+}
+
+func newServer(handle func(conn net.Conn)) (*server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &server{listener: listener}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			s.connections.Add(1)
+			s.wg.Add(1)
+
+			go func() {
+				defer s.wg.Done()
+				defer conn.Close()
+
+				handle(conn)
+			}()
+		}
+	}()
+
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Connections returns the number of accepted connections.
+func (s *server) Connections() int {
+	return int(s.connections.Load())
+}
+
+// Bastion is SSH server forwarding direct-tcpip channels. It accepts
+// BastionUser authenticated with the key from KeyFile.
+type Bastion struct {
+	*server
+	config *ssh.ServerConfig
+	dir    string
+	// KeyFile is the private key file of BastionUser.
+	KeyFile string
+	// KnownHostsFile contains the host key of the bastion.
+	KnownHostsFile string
+
+	mu    sync.Mutex
+	conns []ssh.Conn
+}
+
+// NewBastion starts SSH bastion on random local port.
+func NewBastion() (*Bastion, error) {
+	dir, err := os.MkdirTemp("", "transporttest")
+	if err != nil {
+		return nil, err
+	}
+
+	bastion := &Bastion{dir: dir, KeyFile: filepath.Join(dir, "id_ed25519"),
+		KnownHostsFile: filepath.Join(dir, "known_hosts")}
+
+	if err = bastion.init(); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	return bastion, nil
+}
+
+func (b *Bastion) init() error {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		return err
+	}
+
+	userPublic, userKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	block, err := ssh.MarshalPrivateKey(userKey, "")
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(b.KeyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		return err
+	}
+
+	authorized, err := ssh.NewPublicKey(userPublic)
+	if err != nil {
+		return err
+	}
+
+	b.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() != BastionUser || string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, errors.New("unauthorized")
+			}
+
+			return nil, nil
+		},
+	}
+	b.config.AddHostKey(hostSigner)
+
+	b.server, err = newServer(b.handle)
+	if err != nil {
+		return err
+	}
+
+	line := knownhosts.Line([]string{b.Addr()}, hostSigner.PublicKey()) + "\n"
+
+	return os.WriteFile(b.KnownHostsFile, []byte(line), 0o600)
+}
+
+// URL returns the URL of the bastion with BastionUser.
+func (b *Bastion) URL() string {
+	return "ssh://" + BastionUser + "@" + b.Addr()
+}
+
+// Close stops the bastion and removes key files.
+func (b *Bastion) Close() {
+	_ = b.listener.Close()
+
+	b.mu.Lock()
+	for _, conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+
+	_ = os.RemoveAll(b.dir)
+}
+
+func (b *Bastion) handle(conn net.Conn) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, b.config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+
+	b.mu.Lock()
+	b.conns = append(b.conns, serverConn)
+	b.mu.Unlock()
+
+	go ssh.DiscardRequests(requests)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for channel := range channels {
+		if channel.ChannelType() != "direct-tcpip" {
+			_ = channel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+
+			continue
+		}
+
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+
+		if err := ssh.Unmarshal(channel.ExtraData(), &target); err != nil {
+			_ = channel.Reject(ssh.ConnectionFailed, err.Error())
+
+			continue
+		}
+
+		address := net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port)))
+
+		backend, err := net.Dial("tcp", address)
+		if err != nil {
+			_ = channel.Reject(ssh.ConnectionFailed, err.Error())
+
+			continue
+		}
+
+		accepted, channelRequests, err := channel.Accept()
+		if err != nil {
+			_ = backend.Close()
+
+			continue
+		}
+
+		go ssh.DiscardRequests(channelRequests)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			pipe(accepted, backend)
+		}()
+	}
+}
+
+// SOCKS5 is SOCKS5 proxy supporting CONNECT command without authentication
+// or with user and password.
+type SOCKS5 struct {
+	*server
+	user     string
+	password string
+}
+
+// NewSOCKS5 starts SOCKS5 proxy on random local port. Authentication is
+// required if user is not empty.
+func NewSOCKS5(user string, password string) (*SOCKS5, error) {
+	proxy := &SOCKS5{user: user, password: password}
+
+	var err error
+	if proxy.server, err = newServer(proxy.handle); err != nil {
+		return nil, err
+	}
+
+	return proxy, nil
+}
+
+// URL returns the URL of the proxy with credentials.
+func (p *SOCKS5) URL() string {
+	if p.user == "" {
+		return "socks5://" + p.Addr()
+	}
+
+	return "socks5://" + p.user + ":" + p.password + "@" + p.Addr()
+}
+
+// Close stops the proxy and waits for forwarded connections.
+func (p *SOCKS5) Close() {
+	_ = p.listener.Close()
+	p.wg.Wait()
+}
+
+func (p *SOCKS5) handle(conn net.Conn) {
+	address, err := p.handshake(conn)
+	if err != nil {
+		return
+	}
+
+	backend, err := net.Dial("tcp", address)
+	if err != nil {
+		// General failure reply.
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+
+		return
+	}
+
+	if _, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		_ = backend.Close()
+
+		return
+	}
+
+	pipe(conn, backend)
+}
+
+// handshake negotiates authentication and reads CONNECT request.
+func (p *SOCKS5) handshake(conn net.Conn) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return "", err
+	}
+
+	if p.user == "" {
+		_, err := conn.Write([]byte{5, 0})
+		if err != nil {
+			return "", err
+		}
+	} else if err := p.authenticate(conn); err != nil {
+		return "", err
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+
+	var host string
+
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+
+		name := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return "", err
+		}
+
+		host = string(name)
+	case 4:
+		ip := make([]byte, 16)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+
+		host = net.IP(ip).String()
+	default:
+		return "", fmt.Errorf("unsupported address type %d", request[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// authenticate checks user and password with RFC 1929 subnegotiation.
+func (p *SOCKS5) authenticate(conn net.Conn) error {
+	if _, err := conn.Write([]byte{5, 2}); err != nil {
+		return err
+	}
+
+	version := make([]byte, 2)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return err
+	}
+
+	user := make([]byte, version[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return err
+	}
+
+	length := make([]byte, 1)
+	if _, err := io.ReadFull(conn, length); err != nil {
+		return err
+	}
+
+	password := make([]byte, length[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return err
+	}
+
+	if string(user) != p.user || string(password) != p.password {
+		_, _ = conn.Write([]byte{1, 1})
+
+		return errors.New("unauthorized")
+	}
+
+	_, err := conn.Write([]byte{1, 0})
+
+	return err
+}
+
+// pipe copies data in both directions until one side closes connection.
+func pipe(a io.ReadWriteCloser, b io.ReadWriteCloser) {
+	defer a.Close()
+	defer b.Close()
+
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(a, b)
+		done <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(b, a)
+		done <- struct{}{}
+	}()
+
+	<-done
+}
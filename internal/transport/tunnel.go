@@ -0,0 +1,265 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+)
+
+// DefaultSSHPort is the port of SSH bastion if it is not set in the URL.
+const DefaultSSHPort = "22"
+
+var (
+	// ErrUnsupportedScheme is returned when tunnel or proxy URL has
+	// unsupported scheme.
+	ErrUnsupportedScheme = errors.New("unsupported scheme")
+
+	// ErrNoSSHAuth is returned when neither SSH agent nor key file nor
+	// password is available to authenticate on SSH bastion.
+	ErrNoSSHAuth = errors.New("no ssh auth methods: set key file, password or SSH_AUTH_SOCK")
+)
+
+// SSHOptions contains options of SSH connection to the bastion.
+type SSHOptions struct {
+	// KeyFile is the unencrypted private key file. Keys of SSH agent from
+	// SSH_AUTH_SOCK are also used.
+	KeyFile string
+	// KnownHostsFile verifies the bastion host key. ~/.ssh/known_hosts is
+	// used if it is empty.
+	KnownHostsFile string
+}
+
+// SOCKS5 returns DialFunc connecting through SOCKS5 proxy with the URL in
+// socks5://[user:password@]host:port format. Proxy is dialed with dial.
+func SOCKS5(rawURL string, dial DialFunc) (DialFunc, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, fmt.Errorf("proxy: %w %q", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	var auth *proxy.Auth
+
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", u.Host, auth, contextDialer(dial))
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+
+	// SOCKS5 dialer of x/net implements proxy.ContextDialer.
+	contextual, _ := dialer.(proxy.ContextDialer)
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := contextual.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+
+		return conn, nil
+	}, nil
+}
+
+// contextDialer adapts DialFunc to dialer of x/net proxy.
+type contextDialer DialFunc
+
+func (d contextDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d contextDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+// SSH connects to the SSH bastion with the URL in ssh://[user@]host[:port]
+// format. Bastion is dialed with dial. Current user name is used if it is not
+// set in the URL. Use SSHDial to connect through the returned client.
+func SSH(ctx context.Context, rawURL string, opts SSHOptions, dial DialFunc) (*ssh.Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+
+	if u.Scheme != "ssh" {
+		return nil, fmt.Errorf("ssh: %w %q", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	cfg, agentConn, err := sshConfig(u, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+
+	// Agent is used only for authentication during handshake.
+	if agentConn != nil {
+		defer agentConn.Close()
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), DefaultSSHPort)
+	}
+
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+
+	// Handshake does not accept context, so it is interrupted by closing
+	// the connection.
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, cfg)
+	if err != nil {
+		_ = conn.Close()
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+// SSHDial returns DialFunc connecting to addresses through the SSH client.
+// Channels of SSH do not support deadlines required by protocol clients,
+// so connections are returned as net.Pipe copying data to the channel.
+func SSHDial(client *ssh.Client) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		channel, err := client.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, fmt.Errorf("ssh: %w", err)
+		}
+
+		local, remote := net.Pipe()
+
+		go func() {
+			defer channel.Close()
+			defer remote.Close()
+
+			done := make(chan struct{}, 2)
+
+			go func() {
+				_, _ = io.Copy(channel, remote)
+				done <- struct{}{}
+			}()
+
+			go func() {
+				_, _ = io.Copy(remote, channel)
+				done <- struct{}{}
+			}()
+
+			<-done
+		}()
+
+		return local, nil
+	}
+}
+
+// sshConfig returns SSH client config with auth methods of the URL and
+// options. Connection to SSH agent is returned if it is used.
+func sshConfig(u *url.URL, opts SSHOptions) (*ssh.ClientConfig, net.Conn, error) {
+	name := u.User.Username()
+	if name == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name = current.Username
+	}
+
+	hostKeyCallback, err := knownHosts(opts.KnownHostsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var methods []ssh.AuthMethod
+
+	if opts.KeyFile != "" {
+		signer, err := privateKey(opts.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	var agentConn net.Conn
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if agentConn, err = net.Dial("unix", socket); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+
+	if password, ok := u.User.Password(); ok {
+		methods = append(methods, ssh.Password(password))
+	}
+
+	if len(methods) == 0 {
+		return nil, nil, ErrNoSSHAuth
+	}
+
+	return &ssh.ClientConfig{User: name, Auth: methods, HostKeyCallback: hostKeyCallback}, agentConn, nil
+}
+
+// privateKey reads unencrypted private key from the file.
+func privateKey(name string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(expandHome(name))
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return signer, nil
+}
+
+// knownHosts returns callback verifying host keys with the known hosts file.
+func knownHosts(name string) (ssh.HostKeyCallback, error) {
+	if name == "" {
+		name = "~/.ssh/known_hosts"
+	}
+
+	return knownhosts.New(expandHome(name))
+}
+
+// expandHome replaces ~/ prefix of the file name with home directory.
+func expandHome(name string) string {
+	if !strings.HasPrefix(name, "~/") {
+		return name
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+
+	return filepath.Join(home, name[2:])
+}
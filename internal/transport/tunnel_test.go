@@ -0,0 +1,160 @@
+package transport_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"github.com/gorcon/rcon-cli/internal/transport/transporttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPlainEchoServer starts TCP server echoing lines back.
+func newPlainEchoServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = io.WriteString(conn, scanner.Text()+"\n")
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func echo(t *testing.T, dial transport.DialFunc, address string) {
+	t.Helper()
+
+	conn, err := dial(context.Background(), "tcp", address)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "status\n")
+	assert.NoError(t, err)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "status\n", line)
+}
+
+func TestSOCKS5(t *testing.T) {
+	address := newPlainEchoServer(t)
+
+	t.Run("success", func(t *testing.T) {
+		proxy, err := transporttest.NewSOCKS5("rcon", "secret")
+		assert.NoError(t, err)
+		defer proxy.Close()
+
+		dial, err := transport.SOCKS5(proxy.URL(), transport.Direct)
+		assert.NoError(t, err)
+
+		echo(t, dial, address)
+		assert.Equal(t, 1, proxy.Connections())
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		proxy, err := transporttest.NewSOCKS5("rcon", "secret")
+		assert.NoError(t, err)
+		defer proxy.Close()
+
+		dial, err := transport.SOCKS5("socks5://rcon:wrong@"+proxy.Addr(), transport.Direct)
+		assert.NoError(t, err)
+
+		_, err = dial(context.Background(), "tcp", address)
+		assert.ErrorContains(t, err, "proxy: ")
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := transport.SOCKS5("http://127.0.0.1:3128", transport.Direct)
+		assert.ErrorIs(t, err, transport.ErrUnsupportedScheme)
+	})
+}
+
+func TestSSH(t *testing.T) {
+	address := newPlainEchoServer(t)
+
+	bastion, err := transporttest.NewBastion()
+	assert.NoError(t, err)
+	defer bastion.Close()
+
+	opts := transport.SSHOptions{KeyFile: bastion.KeyFile, KnownHostsFile: bastion.KnownHostsFile}
+
+	t.Run("success", func(t *testing.T) {
+		client, err := transport.SSH(context.Background(), bastion.URL(), opts, transport.Direct)
+		assert.NoError(t, err)
+		defer client.Close()
+
+		// Connections are multiplexed over one SSH connection.
+		echo(t, transport.SSHDial(client), address)
+		echo(t, transport.SSHDial(client), address)
+	})
+
+	t.Run("through proxy", func(t *testing.T) {
+		proxy, err := transporttest.NewSOCKS5("", "")
+		assert.NoError(t, err)
+		defer proxy.Close()
+
+		dial, err := transport.SOCKS5(proxy.URL(), transport.Direct)
+		assert.NoError(t, err)
+
+		client, err := transport.SSH(context.Background(), bastion.URL(), opts, dial)
+		assert.NoError(t, err)
+		defer client.Close()
+
+		echo(t, transport.SSHDial(client), address)
+		assert.Equal(t, 1, proxy.Connections())
+	})
+
+	t.Run("unknown host key", func(t *testing.T) {
+		knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+		assert.NoError(t, os.WriteFile(knownHosts, nil, 0o600))
+
+		_, err := transport.SSH(context.Background(), bastion.URL(),
+			transport.SSHOptions{KeyFile: bastion.KeyFile, KnownHostsFile: knownHosts}, transport.Direct)
+		assert.ErrorContains(t, err, "key is unknown")
+	})
+
+	t.Run("wrong user", func(t *testing.T) {
+		_, err := transport.SSH(context.Background(), "ssh://admin@"+bastion.Addr(), opts, transport.Direct)
+		assert.ErrorContains(t, err, "unable to authenticate")
+	})
+
+	t.Run("no auth methods", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+
+		_, err := transport.SSH(context.Background(), bastion.URL(),
+			transport.SSHOptions{KnownHostsFile: bastion.KnownHostsFile}, transport.Direct)
+		assert.ErrorIs(t, err, transport.ErrNoSSHAuth)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := transport.SSH(context.Background(), "socks5://"+bastion.Addr(), opts, transport.Direct)
+		assert.ErrorIs(t, err, transport.ErrUnsupportedScheme)
+	})
+}
@@ -0,0 +1,337 @@
+// Package webrcon implements Rust WebRCON client exposing message metadata
+// and unsolicited messages like logs and chat.
+package webrcon
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultDialTimeout provides default auth timeout to remote server.
+	DefaultDialTimeout = 5 * time.Second
+
+	// DefaultDeadline provides default deadline to read and write operations
+	// of commands.
+	DefaultDeadline = 5 * time.Second
+
+	// MaxCommandLen is an artificial restriction, but it will help in case
+	// of random large queries.
+	MaxCommandLen = 1000
+)
+
+// Message types sent by Rust server.
+const (
+	TypeGeneric = "Generic"
+	TypeLog     = "Log"
+	TypeWarning = "Warning"
+	TypeError   = "Error"
+	TypeChat    = "Chat"
+	TypeReport  = "Report"
+)
+
+var (
+	// ErrAuthFailed is returned when remote server refuses the password.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrCommandTooLong is returned when executed command length is bigger
+	// than MaxCommandLen characters.
+	ErrCommandTooLong = errors.New("command too long")
+
+	// ErrCommandEmpty is returned when executed command length equal 0.
+	ErrCommandEmpty = errors.New("command too small")
+
+	// ErrUnsupportedScheme is returned when address URL scheme is not ws
+	// or wss.
+	ErrUnsupportedScheme = errors.New("unsupported address scheme")
+
+	// ErrInsecureScheme is returned when address URL has ws scheme, but TLS
+	// is enabled.
+	ErrInsecureScheme = errors.New("ws address scheme with TLS: use wss scheme")
+)
+
+// malformedClose is the handshake error when Rust server closes connection
+// with wrong password instead of upgrading it.
+const malformedClose = `malformed HTTP response "\x88\x02\x03\xe8"`
+
+// Message is the request and response payload of WebRCON.
+type Message struct {
+	// Message is the command in requests and the text in responses.
+	Message string `json:"Message"`
+	// Identifier matches responses to requests. Server messages which are
+	// not responses, e.g. logs and chat, have zero or negative identifier.
+	Identifier int `json:"Identifier"`
+	// Type is one of Generic, Log, Warning, Error, Chat or Report. It is
+	// empty in requests.
+	Type       string `json:"Type,omitempty"`
+	Stacktrace string `json:"Stacktrace,omitempty"`
+	// Name is the requester name shown in server logs.
+	Name string `json:"Name,omitempty"`
+}
+
+// Settings contains options of Conn.
+type Settings struct {
+	dialTimeout time.Duration
+	deadline    time.Duration
+	name        string
+	identifier  int
+	tlsConfig   *tls.Config
+	dial        func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DefaultSettings provides default settings to Conn.
+var DefaultSettings = Settings{
+	dialTimeout: DefaultDialTimeout,
+	deadline:    DefaultDeadline,
+}
+
+// Option allows to inject settings to Settings.
+type Option func(s *Settings)
+
+// SetDialTimeout injects dial timeout to Settings.
+func SetDialTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.dialTimeout = timeout
+	}
+}
+
+// SetDeadline injects read and write timeout of commands to Settings.
+func SetDeadline(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.deadline = timeout
+	}
+}
+
+// SetName injects the requester name sent with commands to Settings.
+func SetName(name string) Option {
+	return func(s *Settings) {
+		s.name = name
+	}
+}
+
+// SetIdentifier injects the identifier sent with all commands to Settings.
+// Identifiers are generated for each command if it is not positive.
+func SetIdentifier(identifier int) Option {
+	return func(s *Settings) {
+		s.identifier = identifier
+	}
+}
+
+// SetTLSConfig injects TLS config to Settings. Addresses without scheme are
+// dialed with wss scheme if it is set.
+func SetTLSConfig(cfg *tls.Config) Option {
+	return func(s *Settings) {
+		s.tlsConfig = cfg
+	}
+}
+
+// SetNetDialContext injects the function creating TCP connections, e.g.
+// through tunnels, to Settings.
+func SetNetDialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) Option {
+	return func(s *Settings) {
+		s.dial = dial
+	}
+}
+
+// Conn represents a WebRCON connection.
+type Conn struct {
+	conn     *websocket.Conn
+	settings Settings
+	// identifier is the last generated request identifier.
+	identifier atomic.Int32
+}
+
+// Dial creates a new authorized WebRCON connection.
+func Dial(address string, password string, options ...Option) (*Conn, error) {
+	return DialContext(context.Background(), address, password, options...)
+}
+
+// DialContext creates a new authorized WebRCON connection. Dial is
+// interrupted when the context is done. Address is host:port or ws:// and
+// wss:// URL with optional path to which the password is appended.
+func DialContext(ctx context.Context, address string, password string, options ...Option) (*Conn, error) {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = settings.dialTimeout
+	dialer.TLSClientConfig = settings.tlsConfig
+	dialer.NetDialContext = settings.dial
+
+	u, err := URL(address, password, settings.tlsConfig != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), nil)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+
+	if err != nil {
+		if err.Error() == malformedClose || isUnauthorized(resp) {
+			return nil, ErrAuthFailed
+		}
+
+		return nil, fmt.Errorf("webrcon: %w", err)
+	}
+
+	return &Conn{conn: conn, settings: settings}, nil
+}
+
+// URL returns the URL of WebRCON endpoint with the password. Address is
+// host:port, dialed with wss scheme if secure is true, or ws:// and wss:// URL
+// with optional path. ws:// URL is refused if secure is true, so the
+// connection is never sent in plaintext when TLS is requested.
+func URL(address string, password string, secure bool) (*url.URL, error) {
+	if !strings.Contains(address, "://") {
+		u := &url.URL{Scheme: "ws", Host: address, Path: "/" + password}
+		if secure {
+			u.Scheme = "wss"
+		}
+
+		return u, nil
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("webrcon: %w", err)
+	}
+
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("webrcon: %w %q", ErrUnsupportedScheme, u.Scheme)
+	}
+
+	if u.Scheme == "ws" && secure {
+		return nil, fmt.Errorf("webrcon: %w", ErrInsecureScheme)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + password
+	u.RawPath = ""
+
+	return u, nil
+}
+
+// isUnauthorized reports whether the handshake was refused by proxies or
+// servers answering wrong password with http status.
+func isUnauthorized(resp *http.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden)
+}
+
+// Execute sends command string to execute to the remote server.
+func (c *Conn) Execute(command string) (string, error) {
+	response, err := c.ExecuteMessage(command)
+
+	return response.Message, err
+}
+
+// ExecuteMessage sends command to the remote server and returns the response
+// with metadata. Messages with other identifiers received before the response
+// are skipped, the deadline is not extended by them.
+func (c *Conn) ExecuteMessage(command string) (Message, error) {
+	if command == "" {
+		return Message{}, ErrCommandEmpty
+	}
+
+	if len(command) > MaxCommandLen {
+		return Message{}, ErrCommandTooLong
+	}
+
+	request := Message{Message: command, Identifier: c.settings.identifier, Name: c.settings.name}
+	if request.Identifier <= 0 {
+		request.Identifier = int(c.identifier.Add(1))
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return Message{}, fmt.Errorf("webrcon: %w", err)
+	}
+
+	if err := c.write(data); err != nil {
+		return Message{}, err
+	}
+
+	var until time.Time
+	if c.settings.deadline != 0 {
+		until = time.Now().Add(c.settings.deadline)
+	}
+
+	for {
+		response, err := c.read(until)
+		if err != nil {
+			return Message{}, err
+		}
+
+		if response.Identifier == request.Identifier {
+			return response, nil
+		}
+	}
+}
+
+// Read waits for the next message from the remote server without deadline.
+// It is used to follow logs and chat. Close the connection to interrupt it.
+func (c *Conn) Read() (Message, error) {
+	return c.read(time.Time{})
+}
+
+// LocalAddr returns the local network address.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) write(data []byte) error {
+	if c.settings.deadline != 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.settings.deadline)); err != nil {
+			return fmt.Errorf("webrcon: %w", err)
+		}
+	}
+
+	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("webrcon: %w", err)
+	}
+
+	return nil
+}
+
+// read reads the next message until the time. Zero time waits forever.
+func (c *Conn) read(until time.Time) (Message, error) {
+	if err := c.conn.SetReadDeadline(until); err != nil {
+		return Message{}, fmt.Errorf("webrcon: %w", err)
+	}
+
+	_, p, err := c.conn.ReadMessage()
+	if err != nil {
+		return Message{}, fmt.Errorf("webrcon: %w", err)
+	}
+
+	var message Message
+	if err := json.Unmarshal(p, &message); err != nil {
+		return Message{}, fmt.Errorf("webrcon: %w", err)
+	}
+
+	return message, nil
+}
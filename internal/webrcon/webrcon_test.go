@@ -0,0 +1,258 @@
+package webrcon_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newServer starts WebRCON server which sends log message on connect and
+// chat message before each response and records received requests.
+func newServer(t *testing.T, requests chan<- webrcon.Message) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(handler(requests))
+}
+
+// handler serves WebRCON with password at the end of any path like servers
+// behind TLS proxies.
+func handler(requests chan<- webrcon.Message) http.Handler {
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/password") {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		_ = ws.WriteJSON(webrcon.Message{Message: "Saved 100 ents", Type: webrcon.TypeLog})
+
+		for {
+			var request webrcon.Message
+			if err := ws.ReadJSON(&request); err != nil {
+				return
+			}
+
+			if requests != nil {
+				requests <- request
+			}
+
+			_ = ws.WriteJSON(webrcon.Message{Message: `{"Message":"hi"}`, Identifier: -1, Type: webrcon.TypeChat})
+
+			if request.Message == "silence" {
+				continue
+			}
+
+			if request.Message == "chatty" {
+				for i := 0; i < 10; i++ {
+					time.Sleep(30 * time.Millisecond)
+					_ = ws.WriteJSON(webrcon.Message{Message: "Saved 100 ents", Type: webrcon.TypeLog})
+				}
+
+				continue
+			}
+
+			response := webrcon.Message{Message: "Command '" + request.Message + "' not found", Identifier: request.Identifier, Type: webrcon.TypeWarning}
+			if request.Message == "fail" {
+				response = webrcon.Message{Message: "boom", Identifier: request.Identifier, Type: webrcon.TypeError, Stacktrace: "at Foo()"}
+			}
+
+			_ = ws.WriteJSON(response)
+		}
+	})
+}
+
+func addr(server *httptest.Server) string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestDial(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	t.Run("auth failed", func(t *testing.T) {
+		_, err := webrcon.Dial(addr(server), "wrong")
+		assert.ErrorIs(t, err, webrcon.ErrAuthFailed)
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		_, err := webrcon.Dial("127.0.0.1:1", "password")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, webrcon.ErrAuthFailed)
+	})
+}
+
+func TestDial_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(handler(nil))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	t.Run("wss url with path", func(t *testing.T) {
+		conn, err := webrcon.Dial("wss://"+address+"/rust/rcon/", "password",
+			webrcon.SetTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}))
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		result, err := conn.Execute("status")
+		assert.NoError(t, err)
+		assert.Equal(t, "Command 'status' not found", result)
+	})
+
+	t.Run("address with tls config", func(t *testing.T) {
+		conn, err := webrcon.Dial(address, "password",
+			webrcon.SetTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}))
+		assert.NoError(t, err)
+		defer conn.Close()
+	})
+
+	t.Run("unknown authority", func(t *testing.T) {
+		_, err := webrcon.Dial("wss://"+address, "password")
+		var unknown x509.UnknownAuthorityError
+		assert.ErrorAs(t, err, &unknown)
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		_, err := webrcon.Dial("wss://"+address, "wrong",
+			webrcon.SetTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}))
+		assert.ErrorIs(t, err, webrcon.ErrAuthFailed)
+	})
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		address string
+		secure  bool
+		want    string
+	}{
+		{address: "127.0.0.1:28016", want: "ws://127.0.0.1:28016/password"},
+		{address: "127.0.0.1:28016", secure: true, want: "wss://127.0.0.1:28016/password"},
+		{address: "ws://127.0.0.1:28016", want: "ws://127.0.0.1:28016/password"},
+		{address: "wss://rcon.example.com/rust/", want: "wss://rcon.example.com/rust/password"},
+		{address: "[::1]:28016", want: "ws://[::1]:28016/password"},
+	}
+
+	for _, test := range tests {
+		u, err := webrcon.URL(test.address, "password", test.secure)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, u.String())
+	}
+
+	_, err := webrcon.URL("https://rcon.example.com", "password", false)
+	assert.ErrorIs(t, err, webrcon.ErrUnsupportedScheme)
+
+	_, err = webrcon.URL("ws://127.0.0.1:28016", "password", true)
+	assert.ErrorIs(t, err, webrcon.ErrInsecureScheme)
+}
+
+func TestConn_ExecuteMessage(t *testing.T) {
+	requests := make(chan webrcon.Message, 10)
+
+	server := newServer(t, requests)
+	defer server.Close()
+
+	// Test chat message is skipped and identifiers are generated.
+	t.Run("metadata", func(t *testing.T) {
+		conn, err := webrcon.Dial(addr(server), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		response, err := conn.ExecuteMessage("fail")
+		assert.NoError(t, err)
+		assert.Equal(t, webrcon.Message{Message: "boom", Identifier: 1, Type: webrcon.TypeError, Stacktrace: "at Foo()"}, response)
+
+		result, err := conn.Execute("status")
+		assert.NoError(t, err)
+		assert.Equal(t, "Command 'status' not found", result)
+
+		assert.Equal(t, webrcon.Message{Message: "fail", Identifier: 1}, <-requests)
+		assert.Equal(t, webrcon.Message{Message: "status", Identifier: 2}, <-requests)
+	})
+
+	t.Run("name and identifier", func(t *testing.T) {
+		conn, err := webrcon.Dial(addr(server), "password", webrcon.SetName("bot"), webrcon.SetIdentifier(42))
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		response, err := conn.ExecuteMessage("status")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, response.Identifier)
+		assert.Equal(t, webrcon.Message{Message: "status", Identifier: 42, Name: "bot"}, <-requests)
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		conn, err := webrcon.Dial(addr(server), "password")
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Execute("")
+		assert.ErrorIs(t, err, webrcon.ErrCommandEmpty)
+
+		_, err = conn.Execute(strings.Repeat("a", webrcon.MaxCommandLen+1))
+		assert.ErrorIs(t, err, webrcon.ErrCommandTooLong)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		conn, err := webrcon.Dial(addr(server), "password", webrcon.SetDeadline(100*time.Millisecond))
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Execute("silence")
+		assert.ErrorContains(t, err, "timeout")
+		<-requests
+	})
+
+	// Test unsolicited messages do not extend the deadline.
+	t.Run("deadline chatty server", func(t *testing.T) {
+		conn, err := webrcon.Dial(addr(server), "password", webrcon.SetDeadline(100*time.Millisecond))
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		start := time.Now()
+
+		_, err = conn.Execute("chatty")
+		assert.ErrorContains(t, err, "timeout")
+		assert.Less(t, time.Since(start), 250*time.Millisecond)
+		<-requests
+	})
+}
+
+func TestConn_Read(t *testing.T) {
+	server := newServer(t, nil)
+	defer server.Close()
+
+	conn, err := webrcon.Dial(addr(server), "password")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	message, err := conn.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, webrcon.Message{Message: "Saved 100 ents", Type: webrcon.TypeLog}, message)
+
+	// Test Read waits without deadline until the connection is closed.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = conn.Close()
+	}()
+
+	_, err = conn.Read()
+	assert.Error(t, err)
+}
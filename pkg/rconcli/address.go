@@ -0,0 +1,175 @@
+package rconcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/telnet"
+)
+
+var (
+	// ErrNoAddress is returned when the session has neither address nor SRV
+	// record.
+	ErrNoAddress = errors.New("address is not set")
+
+	// ErrAuthFailed is matched by errors of remote servers rejecting
+	// the password and of SSH bastions without auth methods.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrInvalidAddress is returned when address of the session cannot be
+	// parsed.
+	ErrInvalidAddress = transport.ErrInvalidAddress
+
+	// ErrNoSRVRecords is returned when SRV record of the session has
+	// no targets.
+	ErrNoSRVRecords = transport.ErrNoSRVRecords
+)
+
+// resolver looks up SRV records of sessions.
+var resolver transport.Resolver = net.DefaultResolver
+
+// Addresses returns addresses of the session in the order they are tried:
+// targets of the SRV record, the address and the fallback addresses. Ports
+// are defaulted per game profile and protocol. SRV record is resolved
+// locally, also when the session has SOCKS5 proxy or SSH bastion.
+func Addresses(ctx context.Context, ses *Session) ([]string, error) {
+	var addresses []string
+
+	if ses.SRV != "" {
+		targets, err := transport.LookupSRV(ctx, resolver, ses.SRV)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, targets...)
+	}
+
+	for _, address := range append([]string{ses.Address}, ses.Addresses...) {
+		if address == "" {
+			continue
+		}
+
+		joined, err := transport.JoinPort(address, ses.DefaultPort())
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, joined)
+	}
+
+	if len(addresses) == 0 {
+		return nil, ErrNoAddress
+	}
+
+	return addresses, nil
+}
+
+// HostPort returns host:port of the TCP connection to the address returned
+// by Addresses. ws:// and wss:// URLs are connected at their host, the port
+// is defaulted to 80 and 443 respectively.
+func HostPort(address string) (string, error) {
+	if !strings.Contains(address, "://") {
+		return address, nil
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", transport.ErrInvalidAddress, address, err)
+	}
+
+	port := u.Port()
+
+	switch {
+	case port != "":
+	case u.Scheme == "wss":
+		port = "443"
+	default:
+		port = "80"
+	}
+
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// eachAddress calls fn with a copy of the session for each address until
+// fn succeeds. Next addresses are not tried if the password is rejected or
+// the context is done. All errors are returned if no address succeeded.
+func eachAddress[T any](ctx context.Context, ses *Session, fn func(ses *Session) (T, error)) (T, error) {
+	var zero T
+
+	addresses, err := Addresses(ctx, ses)
+	if errors.Is(err, ErrNoAddress) {
+		// Protocol clients report the missing address themselves.
+		result, err := fn(ses)
+
+		return result, wrapAuthError(err)
+	}
+
+	if err != nil {
+		return zero, err
+	}
+
+	errs := make([]error, 0, len(addresses))
+
+	for _, address := range addresses {
+		single := *ses
+		single.Address, single.Addresses, single.SRV = address, nil, ""
+
+		result, err := fn(&single)
+		if err == nil {
+			return result, nil
+		}
+
+		if len(addresses) == 1 || ctx.Err() != nil || isAuthError(err) {
+			return zero, wrapAuthError(err)
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", address, err))
+	}
+
+	return zero, fmt.Errorf("all %d addresses failed: %w", len(addresses), errors.Join(errs...))
+}
+
+// authError is the error of rejected credentials matching ErrAuthFailed.
+// Its message is the message of the protocol error.
+type authError struct {
+	err error
+}
+
+// Error returns the message of the protocol error.
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns ErrAuthFailed and the protocol error.
+func (e *authError) Unwrap() []error {
+	return []error{ErrAuthFailed, e.err}
+}
+
+// wrapAuthError makes the error of rejected credentials match ErrAuthFailed.
+// Other errors are returned as is.
+func wrapAuthError(err error) error {
+	if err == nil || errors.Is(err, ErrAuthFailed) || !isAuthError(err) {
+		return err
+	}
+
+	return &authError{err: err}
+}
+
+// isAuthError returns true if the remote server rejected the password.
+func isAuthError(err error) bool {
+	for _, target := range []error{rcon.ErrAuthFailed, rcon.ErrAuthNotRCON, rcon.ErrInvalidAuthResponse,
+		telnet.ErrAuthFailed, telnet.ErrAuthUnexpectedMessage, webrcon.ErrAuthFailed, transport.ErrNoSSHAuth} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package rconcli_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddresses(t *testing.T) {
+	t.Run("default ports", func(t *testing.T) {
+		addresses, err := rconcli.Addresses(context.Background(), &rconcli.Session{
+			Address:   "rcon.example.com",
+			Addresses: []string{"::1", "[2001:db8::1]:25576"},
+			Game:      "minecraft",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rcon.example.com:25575", "[::1]:25575", "[2001:db8::1]:25576"}, addresses)
+	})
+
+	t.Run("protocol port", func(t *testing.T) {
+		addresses, err := rconcli.Addresses(context.Background(), &rconcli.Session{
+			Address: "127.0.0.1",
+			Type:    rconcli.ProtocolWebRCON,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"127.0.0.1:28016"}, addresses)
+	})
+
+	t.Run("no address", func(t *testing.T) {
+		_, err := rconcli.Addresses(context.Background(), &rconcli.Session{})
+		assert.ErrorIs(t, err, rconcli.ErrNoAddress)
+	})
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"127.0.0.1:28016", "127.0.0.1:28016"},
+		{"ws://127.0.0.1:28016/rcon", "127.0.0.1:28016"},
+		{"ws://rcon.example.com", "rcon.example.com:80"},
+		{"wss://[2001:db8::1]/rcon", "[2001:db8::1]:443"},
+	}
+
+	for _, test := range tests {
+		hostPort, err := rconcli.HostPort(test.address)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, hostPort)
+	}
+
+	_, err := rconcli.HostPort("ws://%zz")
+	assert.Error(t, err)
+}
+
+// closedAddress returns the address nothing listens on.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	return address
+}
+
+func TestDial_Fallback(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	t.Run("second address", func(t *testing.T) {
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+			Address:   closedAddress(t),
+			Addresses: []string{server.Addr()},
+			Password:  "password",
+		})
+		assert.NoError(t, err)
+		defer client.Close()
+
+		result, err := client.Execute("help")
+		assert.NoError(t, err)
+		assert.Equal(t, mock.DefaultResponse, result)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		fallback, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "wrong", responses)
+		assert.NoError(t, err)
+		defer fallback.Close()
+
+		// The fallback accepting the password is not tried.
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+			Address:   server.Addr(),
+			Addresses: []string{fallback.Addr()},
+			Password:  "wrong",
+		})
+		assert.ErrorIs(t, err, rcon.ErrAuthFailed)
+		assert.ErrorIs(t, err, rconcli.ErrAuthFailed)
+		assert.Nil(t, client)
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		first, second := closedAddress(t), closedAddress(t)
+
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+			Address:   first,
+			Addresses: []string{second},
+			Password:  "password",
+		})
+		assert.ErrorContains(t, err, "all 2 addresses failed")
+		assert.ErrorContains(t, err, first)
+		assert.ErrorContains(t, err, second)
+		assert.Nil(t, client)
+	})
+
+	t.Run("ipv6", func(t *testing.T) {
+		server, err := mock.NewServer(rconcli.ProtocolRCON, "[::1]:0", "password", responses)
+		if err != nil {
+			t.Skip("IPv6 loopback is not available")
+		}
+		defer server.Close()
+
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{Address: server.Addr(), Password: "password"})
+		assert.NoError(t, err)
+		defer client.Close()
+
+		result, err := client.Execute("help")
+		assert.NoError(t, err)
+		assert.Equal(t, mock.DefaultResponse, result)
+	})
+}
@@ -0,0 +1,400 @@
+package rconcli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/sourcercon"
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/telnet"
+)
+
+var (
+	// ErrUnsupportedProtocol is returned when session has unsupported
+	// protocol type.
+	ErrUnsupportedProtocol = errors.New("unsupported protocol type")
+
+	// ErrDialTimeout is returned when connection and authorization are not
+	// completed within the session dial timeout.
+	ErrDialTimeout = errors.New("dial timeout")
+
+	// ErrCommandTimeout is returned when command is not completed within
+	// the session command timeout.
+	ErrCommandTimeout = errors.New("command timeout")
+
+	// ErrUnsupportedWebScheme is returned when WebRCON address has a scheme
+	// other than ws and wss.
+	ErrUnsupportedWebScheme = webrcon.ErrUnsupportedScheme
+
+	// ErrInsecureWebScheme is returned when WebRCON address has ws scheme
+	// and TLS is enabled.
+	ErrInsecureWebScheme = webrcon.ErrInsecureScheme
+
+	// ErrInvalidCA is returned when TLS CA file contains no certificates.
+	ErrInvalidCA = transport.ErrInvalidCA
+)
+
+// WebRCON message types.
+const (
+	MessageTypeGeneric = webrcon.TypeGeneric
+	MessageTypeLog     = webrcon.TypeLog
+	MessageTypeWarning = webrcon.TypeWarning
+	MessageTypeError   = webrcon.TypeError
+	MessageTypeChat    = webrcon.TypeChat
+	MessageTypeReport  = webrcon.TypeReport
+)
+
+// Client is an authorized connection to remote server. Execute must not be
+// called concurrently.
+type Client interface {
+	Execute(command string) (string, error)
+	Close() error
+}
+
+// Message is the WebRCON response with metadata. Responses of other
+// protocols contain only the message.
+type Message struct {
+	// Message is the text of the response.
+	Message string `json:"message"`
+	// Identifier matches responses to requests. Server messages which are
+	// not responses, e.g. logs and chat, have zero or negative identifier.
+	Identifier int `json:"identifier,omitempty"`
+	// Type is one of MessageType constants.
+	Type       string `json:"type,omitempty"`
+	Stacktrace string `json:"stacktrace,omitempty"`
+	// Name is the requester name shown in server logs.
+	Name string `json:"name,omitempty"`
+}
+
+// newMessage converts WebRCON protocol message.
+func newMessage(message webrcon.Message) Message {
+	return Message{
+		Message:    message.Message,
+		Identifier: message.Identifier,
+		Type:       message.Type,
+		Stacktrace: message.Stacktrace,
+		Name:       message.Name,
+	}
+}
+
+// MessageClient is Client returning responses with metadata. Clients of
+// WebRCON protocol implement it.
+type MessageClient interface {
+	Client
+	ExecuteMessage(command string) (Message, error)
+}
+
+// Dial connects and authorizes to the remote server of the session within
+// the session dial timeout. Each command of the returned client is limited
+// by the session command timeout. Timeouts are applied the same way for all
+// protocols. Dial returns the context error if the context is done before
+// connection is established. The client opens its own tunnel if the session
+// has SOCKS5 proxy or SSH bastion and closes it with the connection.
+// Addresses of the session are tried in order, each within the dial timeout.
+func Dial(ctx context.Context, ses *Session) (Client, error) {
+	return DialTunnel(ctx, ses, nil)
+}
+
+// DialTunnel connects like Dial through the tunnel opened by OpenTunnel.
+// The tunnel is not closed with the client, so it can be reused.
+func DialTunnel(ctx context.Context, ses *Session, tunnel *Tunnel) (Client, error) {
+	return eachAddress(ctx, ses, func(ses *Session) (Client, error) {
+		return dialTimeout(ctx, ses, tunnel)
+	})
+}
+
+// dialTimeout connects through the tunnel within the session dial timeout.
+// Tunnel is opened and owned by the client if it is nil.
+func dialTimeout(ctx context.Context, ses *Session, tunnel *Tunnel) (Client, error) {
+	dialTimeout := ses.ResolveDialTimeout()
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	type dialed struct {
+		client Client
+		err    error
+	}
+
+	done := make(chan dialed, 1)
+
+	go func() {
+		var d dialed
+
+		if tunnel != nil {
+			d.client, d.err = dial(dialCtx, ses, tunnel, dialTimeout, deadline(ses))
+		} else {
+			d.client, d.err = dialOwnTunnel(dialCtx, ses, dialTimeout, deadline(ses))
+		}
+
+		done <- d
+	}()
+
+	select {
+	case d := <-done:
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		return &timeoutClient{Client: d.client, ses: ses}, nil
+	case <-dialCtx.Done():
+		// Close the connection established after the context is done.
+		go func() {
+			if d := <-done; d.err == nil {
+				_ = d.client.Close()
+			}
+		}()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w after %s", ErrDialTimeout, dialTimeout)
+	}
+}
+
+// dialOwnTunnel opens the tunnel of the session and connects through it.
+// The tunnel is closed with the returned client.
+func dialOwnTunnel(ctx context.Context, ses *Session, dialTimeout, deadline time.Duration) (Client, error) {
+	tunnel, err := OpenTunnel(ctx, ses)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dial(ctx, ses, tunnel, dialTimeout, deadline)
+	if err != nil {
+		_ = tunnel.Close()
+
+		return nil, err
+	}
+
+	if tunnel.direct {
+		return client, nil
+	}
+
+	return &closingClient{Client: client, closer: tunnel}, nil
+}
+
+// deadline returns read and write deadline of protocol clients. Timeouts
+// are enforced by contexts, the deadline only guarantees that hung
+// connections are released, so it is longer than any timeout.
+func deadline(ses *Session) time.Duration {
+	return ses.ResolveDialTimeout() + ses.MaxCommandTimeout()
+}
+
+// dial connects to the remote server with the protocol of the session
+// through the tunnel. RCON and TELNET clients connect through the local
+// forwarder if TLS is enabled or the tunnel is not direct.
+func dial(ctx context.Context, ses *Session, tunnel *Tunnel, dialTimeout, deadline time.Duration) (Client, error) {
+	switch ses.Type {
+	case "", ProtocolRCON, ProtocolTELNET:
+	case ProtocolWebRCON:
+		options, err := webOptions(ses, tunnel)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := webrcon.DialContext(ctx, ses.Address, ses.Password, append(options,
+			webrcon.SetDeadline(deadline), webrcon.SetIdentifier(ses.WebIdentifier))...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &webClient{Conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedProtocol, ses.Type)
+	}
+
+	if !ses.TLSEnabled() && tunnel.direct {
+		return dialPlain(ses, ses.Address, dialTimeout, deadline)
+	}
+
+	forwarder, err := tunnel.Forward(ctx, ses)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dialPlain(ses, forwarder.Addr(), dialTimeout, deadline)
+	if err != nil {
+		_ = forwarder.Close()
+
+		return nil, err
+	}
+
+	return &closingClient{Client: client, closer: forwarder}, nil
+}
+
+// dialPlain connects to the address with RCON or TELNET protocol of
+// the session. RCON responses split into multiple packets are reassembled
+// by the end of response strategy of the session.
+func dialPlain(ses *Session, address string, dialTimeout time.Duration, deadline time.Duration) (Client, error) {
+	if ses.Type == ProtocolTELNET {
+		return telnet.Dial(address, ses.Password, telnet.SetDialTimeout(dialTimeout))
+	}
+
+	return sourcercon.Dial(address, ses.Password, sourcercon.SetDialTimeout(dialTimeout), sourcercon.SetDeadline(deadline),
+		sourcercon.SetEnd(ses.ResolveResponseEnd()), sourcercon.SetIdleTimeout(ses.ResolveResponseIdle()))
+}
+
+// webOptions returns options of WebRCON connection of the session through
+// the tunnel.
+func webOptions(ses *Session, tunnel *Tunnel) ([]webrcon.Option, error) {
+	options := []webrcon.Option{webrcon.SetDialTimeout(ses.ResolveDialTimeout()), webrcon.SetName(ses.WebName)}
+
+	if !tunnel.direct {
+		options = append(options, webrcon.SetNetDialContext(tunnel.DialContext))
+	}
+
+	if !ses.TLSEnabled() {
+		return options, nil
+	}
+
+	u, err := webrcon.URL(ses.Address, ses.Password, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := tlsConfig(ses, u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(options, webrcon.SetTLSConfig(cfg)), nil
+}
+
+// tlsConfig returns TLS config of the session connecting to the address.
+func tlsConfig(ses *Session, address string) (*tls.Config, error) {
+	var opts transport.TLSOptions
+
+	if ses.TLS != nil {
+		opts = transport.TLSOptions{
+			CAFile:             ses.TLS.CAFile,
+			CertFile:           ses.TLS.CertFile,
+			KeyFile:            ses.TLS.KeyFile,
+			InsecureSkipVerify: ses.TLS.InsecureSkipVerify,
+			ServerName:         ses.TLS.ServerName,
+		}
+	}
+
+	return transport.TLSConfig(opts, address)
+}
+
+// webClient is MessageClient of WebRCON protocol.
+type webClient struct {
+	*webrcon.Conn
+}
+
+// ExecuteMessage executes the command and returns the response with metadata.
+func (c *webClient) ExecuteMessage(command string) (Message, error) {
+	message, err := c.Conn.ExecuteMessage(command)
+
+	return newMessage(message), err
+}
+
+// closingClient is Client closing the forwarder or the tunnel it is
+// connected through with the connection.
+type closingClient struct {
+	Client
+	closer io.Closer
+}
+
+// ExecuteMessage executes the command and returns the response with metadata
+// if the connection supports it.
+func (c *closingClient) ExecuteMessage(command string) (Message, error) {
+	if mc, ok := c.Client.(MessageClient); ok {
+		return mc.ExecuteMessage(command)
+	}
+
+	response, err := c.Client.Execute(command)
+
+	return Message{Message: response}, err
+}
+
+// Close closes the connection and the forwarder or the tunnel.
+func (c *closingClient) Close() error {
+	err := c.Client.Close()
+	_ = c.closer.Close()
+
+	return err
+}
+
+// timeoutClient is Client limiting each command by the session command
+// timeout. Connection is closed if command timed out.
+type timeoutClient struct {
+	Client
+	ses *Session
+}
+
+// Execute executes the command within its timeout.
+func (c *timeoutClient) Execute(command string) (string, error) {
+	response, err := c.ExecuteMessage(command)
+
+	return response.Message, err
+}
+
+// ExecuteMessage executes the command within its timeout and returns
+// the response with metadata if the protocol supports it.
+func (c *timeoutClient) ExecuteMessage(command string) (Message, error) {
+	timeout := c.ses.ResolveCommandTimeout(command)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	response, err := ExecuteMessage(ctx, c.Client, command)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return response, fmt.Errorf("%w after %s", ErrCommandTimeout, timeout)
+	}
+
+	return response, err
+}
+
+// Execute executes the command on the client. If the context is done before
+// the response is received, the client is closed to interrupt the command
+// and the context error is returned. The client must not be used after that.
+func Execute(ctx context.Context, client Client, command string) (string, error) {
+	response, err := ExecuteMessage(ctx, client, command)
+
+	return response.Message, err
+}
+
+// ExecuteMessage executes the command on the client like Execute and returns
+// the response with metadata. Responses of clients which do not implement
+// MessageClient contain only the message.
+func ExecuteMessage(ctx context.Context, client Client, command string) (Message, error) {
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
+
+	type executed struct {
+		response Message
+		err      error
+	}
+
+	done := make(chan executed, 1)
+
+	go func() {
+		var e executed
+
+		if mc, ok := client.(MessageClient); ok {
+			e.response, e.err = mc.ExecuteMessage(command)
+		} else {
+			e.response.Message, e.err = client.Execute(command)
+		}
+
+		done <- e
+	}()
+
+	select {
+	case e := <-done:
+		return e.response, e.err
+	case <-ctx.Done():
+		_ = client.Close()
+
+		return Message{}, ctx.Err()
+	}
+}
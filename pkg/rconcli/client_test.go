@@ -0,0 +1,283 @@
+package rconcli_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDial(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	for _, protocol := range []string{rconcli.ProtocolRCON, rconcli.ProtocolTELNET, rconcli.ProtocolWebRCON} {
+		protocol := protocol
+
+		t.Run(protocol, func(t *testing.T) {
+			server, err := mock.NewServer(protocol, "127.0.0.1:0", "password", responses)
+			assert.NoError(t, err)
+			defer server.Close()
+
+			client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+				Address:  server.Addr(),
+				Password: "password",
+				Type:     protocol,
+			})
+			assert.NoError(t, err)
+			defer client.Close()
+
+			result, err := client.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, mock.DefaultResponse, result)
+		})
+	}
+
+	t.Run("unsupported protocol", func(t *testing.T) {
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{Type: "pigeon post"})
+		assert.ErrorIs(t, err, rconcli.ErrUnsupportedProtocol)
+		assert.Nil(t, client)
+	})
+
+	t.Run("dial timeout", func(t *testing.T) {
+		// Listener accepts connections but never responds to auth.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		for _, protocol := range []string{rconcli.ProtocolRCON, rconcli.ProtocolTELNET} {
+			client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+				Address:     listener.Addr().String(),
+				Password:    "password",
+				Type:        protocol,
+				DialTimeout: 50 * time.Millisecond,
+			})
+			assert.ErrorIs(t, err, rconcli.ErrDialTimeout, protocol)
+			assert.Nil(t, client)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		// Listener accepts connections but never responds to auth.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		client, err := rconcli.Dial(ctx, &rconcli.Session{Address: listener.Addr().String(), Password: "password"})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, client)
+	})
+}
+
+// newTLSProxy starts TLS terminating proxy to the target address with
+// the certificate of httptest like stunnel and returns its address and CA
+// file with the certificate.
+func newTLSProxy(t *testing.T, target string) (string, string) {
+	t.Helper()
+
+	certs := httptest.NewTLSServer(nil)
+	t.Cleanup(certs.Close)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certs.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				backend, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer backend.Close()
+
+				go func() {
+					_, _ = io.Copy(backend, conn)
+					_ = backend.Close()
+				}()
+				_, _ = io.Copy(conn, backend)
+			}()
+		}
+	}()
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: certs.Certificate().Raw}
+
+	if err := os.WriteFile(ca, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return listener.Addr().String(), ca
+}
+
+func TestDial_TLS(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	for _, protocol := range []string{rconcli.ProtocolRCON, rconcli.ProtocolTELNET, rconcli.ProtocolWebRCON} {
+		protocol := protocol
+
+		t.Run(protocol, func(t *testing.T) {
+			server, err := mock.NewServer(protocol, "127.0.0.1:0", "password", responses)
+			assert.NoError(t, err)
+			defer server.Close()
+
+			address, ca := newTLSProxy(t, server.Addr())
+
+			client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+				Address:  address,
+				Password: "password",
+				Type:     protocol,
+				TLS:      &rconcli.TLS{Enabled: true, CAFile: ca},
+			})
+			assert.NoError(t, err)
+			defer client.Close()
+
+			result, err := client.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, mock.DefaultResponse, result)
+		})
+	}
+
+	server, err := mock.NewServer(rconcli.ProtocolWebRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	address, ca := newTLSProxy(t, server.Addr())
+
+	t.Run("wss url", func(t *testing.T) {
+		client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+			Address:  "wss://" + address + "/",
+			Password: "password",
+			Type:     rconcli.ProtocolWebRCON,
+			TLS:      &rconcli.TLS{InsecureSkipVerify: true},
+		})
+		assert.NoError(t, err)
+		defer client.Close()
+
+		result, err := client.Execute("help")
+		assert.NoError(t, err)
+		assert.Equal(t, mock.DefaultResponse, result)
+	})
+
+	t.Run("unknown authority", func(t *testing.T) {
+		for _, protocol := range []string{rconcli.ProtocolRCON, rconcli.ProtocolWebRCON} {
+			client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+				Address:  address,
+				Password: "password",
+				Type:     protocol,
+				TLS:      &rconcli.TLS{Enabled: true},
+			})
+
+			var unknown x509.UnknownAuthorityError
+			assert.ErrorAs(t, err, &unknown, protocol)
+			assert.Nil(t, client)
+		}
+	})
+
+	t.Run("server name mismatch", func(t *testing.T) {
+		_, err := rconcli.Dial(context.Background(), &rconcli.Session{
+			Address:  address,
+			Password: "password",
+			Type:     rconcli.ProtocolWebRCON,
+			TLS:      &rconcli.TLS{Enabled: true, CAFile: ca, ServerName: "rcon.example.org"},
+		})
+
+		var hostname x509.HostnameError
+		assert.ErrorAs(t, err, &hostname)
+	})
+}
+
+// blockingClient is Client which Execute blocks until Close is called.
+type blockingClient struct {
+	closed chan struct{}
+}
+
+func (c *blockingClient) Execute(string) (string, error) {
+	<-c.closed
+
+	return "", net.ErrClosed
+}
+
+func (c *blockingClient) Close() error {
+	close(c.closed)
+
+	return nil
+}
+
+func TestDial_ResponseEnd(t *testing.T) {
+	responses := &mock.Responses{Default: strings.Repeat("sv_cheats 0\n", 1000)}
+
+	server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	sessions := map[string]*rconcli.Session{
+		"game profile": {Address: server.Addr(), Password: "password", Game: "minecraft"},
+		"idle":         {Address: server.Addr(), Password: "password", ResponseEnd: rconcli.ResponseEndIdle},
+	}
+
+	for name, ses := range sessions {
+		ses := ses
+
+		t.Run(name, func(t *testing.T) {
+			client, err := rconcli.Dial(context.Background(), ses)
+			assert.NoError(t, err)
+			defer client.Close()
+
+			// Long response is reassembled from multiple packets.
+			result, err := client.Execute("cvarlist")
+			assert.NoError(t, err)
+			assert.Equal(t, responses.Default, result)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("no errors", func(t *testing.T) {
+		result, err := rconcli.Execute(context.Background(), &client{}, "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "players : 0 (500 max)\n", result)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		blocking := &blockingClient{closed: make(chan struct{})}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := rconcli.Execute(ctx, blocking, "status")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		select {
+		case <-blocking.closed:
+		default:
+			t.Error("client is not closed")
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package rconcli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorcon/rcon-cli/internal/codec"
+	"github.com/gorcon/rcon-cli/internal/game"
+	"github.com/gorcon/rcon-cli/internal/transport"
+)
+
+// DefaultConfigName sets the default config file name.
+const DefaultConfigName = "rcon.yaml"
+
+// DefaultConfigEnv is the name of the environment, which is taken
+// as default unless another value is passed.
+const DefaultConfigEnv = "default"
+
+// metricName matches valid Prometheus metric names.
+var metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+var (
+	// ErrConfigValidation is when config validation completed with errors.
+	ErrConfigValidation = errors.New("config validation error")
+
+	// ErrUnsupportedFileExt is returned when config file has an unsupported
+	// extension. Allowed extensions is `.json`, `.yml`, `.yaml`.
+	ErrUnsupportedFileExt = codec.ErrUnsupportedFileExt
+)
+
+// Config allows to take a remote server address and password from
+// the configuration file. This enables not to specify these flags when
+// running the CLI.
+//
+// Example:
+// ```yaml
+// default:
+//
+//	address: "127.0.0.1:16260"
+//	password: "password"
+//
+// ```.
+type Config map[string]Session
+
+// LoadConfig finds and parses config file with remote server credentials.
+func LoadConfig(name string) (*Config, error) {
+	cfg := new(Config)
+	if err := cfg.ParseFromFile(name); err != nil {
+		return nil, fmt.Errorf("parse file: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// ParseFromFile reads a configuration file from disk and loads its contents into
+// the application's config structure. YAML and JSON files are supported.
+func (cfg *Config) ParseFromFile(name string) error {
+	if name != "" {
+		return codec.ReadFile(name, cfg)
+	}
+
+	home, err := filepath.Abs(filepath.Dir(os.Args[0]))
+	if err != nil {
+		return fmt.Errorf("get abs path: %w", err)
+	}
+
+	name = home + "/" + DefaultConfigName
+	if err = codec.ReadFile(name, cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	*cfg = Config{DefaultConfigEnv: {}}
+
+	return nil
+}
+
+// Validate validates the config fields.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return fmt.Errorf("%w: config is not set", ErrConfigValidation)
+	}
+
+	for key, ses := range *cfg {
+		switch ses.Type {
+		case "", ProtocolRCON, ProtocolTELNET, ProtocolWebRCON:
+		default:
+			return fmt.Errorf("%w: unsupported type in %s environment", ErrConfigValidation, key)
+		}
+
+		if err := validateTransport(key, &ses); err != nil {
+			return err
+		}
+
+		if ses.Game != "" {
+			if _, err := game.Lookup(ses.Game); err != nil {
+				return fmt.Errorf("%w: %v in %s environment", ErrConfigValidation, err, key)
+			}
+		}
+
+		switch ses.ResponseEnd {
+		case "", ResponseEndSingle, ResponseEndSentinel, ResponseEndMirror, ResponseEndIdle:
+		default:
+			return fmt.Errorf("%w: unsupported response_end %q in %s environment", ErrConfigValidation,
+				ses.ResponseEnd, key)
+		}
+
+		if ses.ResponseIdle < 0 {
+			return fmt.Errorf("%w: response_idle must not be negative in %s environment", ErrConfigValidation, key)
+		}
+
+		if ses.ResponseIdle != 0 && ses.ResponseIdle >= ses.minCommandTimeout() {
+			return fmt.Errorf("%w: response_idle must be shorter than command timeout in %s environment",
+				ErrConfigValidation, key)
+		}
+
+		if err := validateMetrics(key, ses.Metrics); err != nil {
+			return err
+		}
+
+		if err := validateTimeouts(key, &ses); err != nil {
+			return err
+		}
+
+		if err := validateCommandLists(key, &ses); err != nil {
+			return err
+		}
+
+		(*cfg)[key] = ses
+	}
+
+	return nil
+}
+
+// validateTransport validates the address scheme, TLS and tunnel options of
+// the environment.
+func validateTransport(key string, ses *Session) error {
+	if strings.Contains(ses.Address, "://") {
+		if !strings.HasPrefix(ses.Address, "ws://") && !strings.HasPrefix(ses.Address, "wss://") {
+			return fmt.Errorf("%w: unsupported address scheme in %s environment", ErrConfigValidation, key)
+		}
+
+		if ses.Type != ProtocolWebRCON {
+			return fmt.Errorf("%w: ws and wss addresses require web type in %s environment", ErrConfigValidation, key)
+		}
+
+		if strings.HasPrefix(ses.Address, "ws://") && ses.TLSEnabled() {
+			return fmt.Errorf("%w: ws address with tls enabled in %s environment: use wss scheme",
+				ErrConfigValidation, key)
+		}
+	}
+
+	for _, address := range append([]string{ses.Address}, ses.Addresses...) {
+		if address == "" {
+			continue
+		}
+
+		if _, err := transport.JoinPort(address, ses.DefaultPort()); err != nil {
+			return fmt.Errorf("%w: %v in %s environment", ErrConfigValidation, err, key)
+		}
+	}
+
+	if ses.Via != "" && !strings.HasPrefix(ses.Via, "ssh://") {
+		return fmt.Errorf("%w: via must be ssh:// url in %s environment", ErrConfigValidation, key)
+	}
+
+	if ses.Proxy != "" && !strings.HasPrefix(ses.Proxy, "socks5://") && !strings.HasPrefix(ses.Proxy, "socks5h://") {
+		return fmt.Errorf("%w: proxy must be socks5:// url in %s environment", ErrConfigValidation, key)
+	}
+
+	if ses.TLS != nil && (ses.TLS.CertFile == "") != (ses.TLS.KeyFile == "") {
+		return fmt.Errorf("%w: tls cert_file and key_file must be set together in %s environment",
+			ErrConfigValidation, key)
+	}
+
+	return nil
+}
+
+// validateMetrics validates metrics of the environment.
+func validateMetrics(key string, metrics []Metric) error {
+	for _, metric := range metrics {
+		if !metricName.MatchString(metric.Name) {
+			return fmt.Errorf("%w: invalid metric name %q in %s environment", ErrConfigValidation, metric.Name, key)
+		}
+
+		if metric.Command == "" {
+			return fmt.Errorf("%w: metric %s command is not set in %s environment", ErrConfigValidation, metric.Name, key)
+		}
+
+		if _, err := regexp.Compile(metric.Regex); err != nil {
+			return fmt.Errorf("%w: metric %s regex in %s environment: %v", ErrConfigValidation, metric.Name, key, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTimeouts validates timeouts of the environment.
+func validateTimeouts(key string, ses *Session) error {
+	if ses.Timeout < 0 || ses.DialTimeout < 0 || ses.CommandTimeout < 0 {
+		return fmt.Errorf("%w: negative timeout in %s environment", ErrConfigValidation, key)
+	}
+
+	for expr, timeout := range ses.CommandTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: command timeout %q is not positive in %s environment", ErrConfigValidation, expr, key)
+		}
+	}
+
+	return nil
+}
+
+// validateCommandLists validates command timeouts, confirm, deny and roles
+// lists of the environment and compiles them.
+func validateCommandLists(key string, ses *Session) error {
+	for name := range ses.Roles {
+		if name == "" {
+			return fmt.Errorf("%w: empty role name in %s environment", ErrConfigValidation, key)
+		}
+	}
+
+	if err := ses.Compile(); err != nil {
+		return fmt.Errorf("%w: %v in %s environment", ErrConfigValidation, err, key)
+	}
+
+	return nil
+}
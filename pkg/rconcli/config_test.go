@@ -0,0 +1,432 @@
+package rconcli_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+// DefaultTestLogName sets the default log file name.
+const DefaultTestLogName = "rcon-test.log"
+
+const ConfigLayoutJSON = `{"%s": {"address": "%s", "password": "%s", "log": "%s", "type": "%s"}}`
+const ConfigLayoutYAML = "%s:\n  address: %s\n  password: %s\n  log: %s\n  type: %s"
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("no errors yaml", func(t *testing.T) {
+		configFileName := "rcon-test-local.yaml"
+		stringBody := fmt.Sprintf(ConfigLayoutYAML, rconcli.DefaultConfigEnv, "", "", DefaultTestLogName, "")
+		createFile(configFileName, stringBody)
+		defer os.Remove(configFileName)
+
+		expected := rconcli.Config{
+			"default": rconcli.Session{Address: "", Password: "", Log: "rcon-test.log"},
+		}
+
+		// Expected config is compiled like the loaded one.
+		assert.NoError(t, expected.Validate())
+
+		cfg, err := rconcli.LoadConfig(configFileName)
+		assert.NoError(t, err)
+		assert.Equal(t, &expected, cfg)
+	})
+
+	t.Run("no errors json", func(t *testing.T) {
+		configFileName := "rcon-test-local.json"
+		stringBody := fmt.Sprintf(ConfigLayoutJSON, rconcli.DefaultConfigEnv, "", "", DefaultTestLogName, "")
+		createFile(configFileName, stringBody)
+		defer os.Remove(configFileName)
+
+		expected := rconcli.Config{
+			rconcli.DefaultConfigEnv: rconcli.Session{Address: "", Password: "", Log: DefaultTestLogName},
+		}
+
+		// Expected config is compiled like the loaded one.
+		assert.NoError(t, expected.Validate())
+
+		cfg, err := rconcli.LoadConfig(configFileName)
+		assert.NoError(t, err)
+		assert.Equal(t, &expected, cfg)
+	})
+
+	t.Run("file not exists", func(t *testing.T) {
+		cfg, err := rconcli.LoadConfig("nonexist.yaml")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		assert.Nil(t, cfg)
+	})
+
+	// Test is valid because of automatic placement of a temporary binary to the
+	// `/tmp` directory.
+	// Expected error message: `parse file: yaml: open /tmp/rcon.yaml: no such file or directory`.
+	t.Run("default file not exists", func(t *testing.T) {
+		cfg, err := rconcli.LoadConfig("")
+		assert.Nil(t, err)
+
+		want := &rconcli.Config{rconcli.DefaultConfigEnv: {}}
+		assert.NoError(t, want.Validate())
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("file is incorrect", func(t *testing.T) {
+		configFileName := "rcon-test-local.yaml"
+		stringBody := fmt.Sprintf("address: \"%s\"\n  password: \"%s\"\n  log: \"%s\"", "", "password", DefaultTestLogName)
+		createFile(configFileName, stringBody)
+		defer os.Remove(configFileName)
+
+		cfg, err := rconcli.LoadConfig(configFileName)
+		assert.EqualError(t, err, "parse file: yaml: line 1: did not find expected key")
+
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("unsupported file extension", func(t *testing.T) {
+		configFileName := "unsupported-local.ini"
+		stringBody := "[genera]\addr="
+		createFile(configFileName, stringBody)
+		defer os.Remove(configFileName)
+
+		cfg, err := rconcli.LoadConfig(configFileName)
+		assert.EqualError(t, err, "parse file: unsupported file extension .ini")
+
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("validation failed", func(t *testing.T) {
+		configFileName := "rcon-test-local.json"
+		stringBody := fmt.Sprintf(ConfigLayoutJSON, rconcli.DefaultConfigEnv, "", "", DefaultTestLogName, "pigeon post")
+		createFile(configFileName, stringBody)
+		defer os.Remove(configFileName)
+
+		cfg, err := rconcli.LoadConfig(configFileName)
+		assert.EqualError(t, err, "config validation error: unsupported type in default environment")
+
+		expected := rconcli.Config{
+			rconcli.DefaultConfigEnv: rconcli.Session{Address: "", Password: "", Log: DefaultTestLogName, Type: "pigeon post"},
+		}
+
+		assert.Equal(t, &expected, cfg)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("initialized empty config", func(t *testing.T) {
+		cfg := new(rconcli.Config)
+		err := cfg.Validate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("not initialized empty config", func(t *testing.T) {
+		var cfg *rconcli.Config
+		err := cfg.Validate()
+		assert.EqualError(t, err, "config validation error: config is not set")
+	})
+
+	t.Run("game", func(t *testing.T) {
+		cfg := rconcli.Config{"pz": rconcli.Session{Game: "zomboid"}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg = rconcli.Config{"pz": rconcli.Session{Game: "pigeon"}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+}
+
+func createFile(name, stringBody string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(stringBody)
+
+	return err
+}
+
+func TestConfig_ValidateMetrics(t *testing.T) {
+	t.Run("valid metric", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Metrics: []rconcli.Metric{{Name: "players", Command: "status", Regex: `players : (\d+)`}},
+		}}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("invalid metric name", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Metrics: []rconcli.Metric{{Name: "players count", Command: "status"}},
+		}}
+		assert.EqualError(t, cfg.Validate(), `config validation error: invalid metric name "players count" in rust environment`)
+	})
+
+	t.Run("empty metric command", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Metrics: []rconcli.Metric{{Name: "players"}},
+		}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: metric players command is not set in rust environment")
+	})
+
+	t.Run("invalid metric regex", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Metrics: []rconcli.Metric{{Name: "players", Command: "status", Regex: "("}},
+		}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+}
+
+func TestConfig_ValidateTimeouts(t *testing.T) {
+	t.Run("valid timeouts", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			DialTimeout:     3 * time.Second,
+			CommandTimeouts: map[string]time.Duration{"^(save|backup)": 2 * time.Minute},
+		}}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("negative timeout", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{DialTimeout: -time.Second}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: negative timeout in rust environment")
+	})
+
+	t.Run("invalid command timeout regex", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{CommandTimeouts: map[string]time.Duration{"(": time.Second}}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+
+	t.Run("zero command timeout", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{CommandTimeouts: map[string]time.Duration{"^save": 0}}}
+		assert.EqualError(t, cfg.Validate(), `config validation error: command timeout "^save" is not positive in rust environment`)
+	})
+}
+
+func TestConfig_ValidateTransport(t *testing.T) {
+	t.Run("valid wss address", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Address: "wss://rcon.example.com/rust",
+			Type:    rconcli.ProtocolWebRCON,
+			TLS:     &rconcli.TLS{CertFile: "client.pem", KeyFile: "client.key"},
+		}}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("wss address of rcon type", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{Address: "wss://rcon.example.com", Type: rconcli.ProtocolRCON}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: ws and wss addresses require web type in rust environment")
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{Address: "https://rcon.example.com", Type: rconcli.ProtocolWebRCON}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: unsupported address scheme in rust environment")
+	})
+
+	t.Run("ws address with tls", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{
+			Address: "ws://rcon.example.com",
+			Type:    rconcli.ProtocolWebRCON,
+			TLS:     &rconcli.TLS{Enabled: true},
+		}}
+		assert.EqualError(t, cfg.Validate(),
+			"config validation error: ws address with tls enabled in rust environment: use wss scheme")
+	})
+
+	t.Run("tunnel", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{Via: "ssh://rcon@bastion", Proxy: "socks5://127.0.0.1:1080"}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg = rconcli.Config{"rust": rconcli.Session{Via: "bastion:22"}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: via must be ssh:// url in rust environment")
+
+		cfg = rconcli.Config{"rust": rconcli.Session{Proxy: "http://127.0.0.1:3128"}}
+		assert.EqualError(t, cfg.Validate(), "config validation error: proxy must be socks5:// url in rust environment")
+	})
+
+	t.Run("fallback addresses", func(t *testing.T) {
+		cfg := rconcli.Config{"default": {Address: "rcon.example.com", Addresses: []string{"[::1]:27015", "::1"}}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg = rconcli.Config{"default": {Address: "rcon.example.com", Addresses: []string{"::1:27015:x"}}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+
+		cfg = rconcli.Config{"default": {Address: "127.0.0.1:70000"}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+
+	t.Run("cert without key", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{TLS: &rconcli.TLS{Enabled: true, CertFile: "client.pem"}}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+}
+
+func TestConfig_ValidateResponseEnd(t *testing.T) {
+	cfg := rconcli.Config{"default": {Address: "127.0.0.1:27015", ResponseEnd: rconcli.ResponseEndMirror}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg = rconcli.Config{"default": {Address: "127.0.0.1:27015", ResponseEnd: "eof"}}
+	assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+
+	cfg = rconcli.Config{"default": {Address: "127.0.0.1:27015", ResponseIdle: -time.Second}}
+	assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+
+	cfg = rconcli.Config{"default": {Address: "127.0.0.1:27015", ResponseIdle: time.Second, CommandTimeout: time.Second}}
+	assert.EqualError(t, cfg.Validate(),
+		"config validation error: response_idle must be shorter than command timeout in default environment")
+}
+
+func TestSession_TLSEnabled(t *testing.T) {
+	assert.False(t, (&rconcli.Session{Address: "127.0.0.1:28016"}).TLSEnabled())
+	assert.False(t, (&rconcli.Session{Address: "ws://127.0.0.1:28016"}).TLSEnabled())
+	assert.True(t, (&rconcli.Session{Address: "wss://rcon.example.com"}).TLSEnabled())
+	assert.True(t, (&rconcli.Session{Address: "127.0.0.1:27015", TLS: &rconcli.TLS{Enabled: true}}).TLSEnabled())
+	assert.False(t, (&rconcli.Session{Address: "127.0.0.1:27015", TLS: &rconcli.TLS{CAFile: "ca.pem"}}).TLSEnabled())
+}
+
+func TestSession_DefaultPort(t *testing.T) {
+	assert.Equal(t, rconcli.DefaultPortRCON, (&rconcli.Session{}).DefaultPort())
+	assert.Equal(t, rconcli.DefaultPortTELNET, (&rconcli.Session{Type: rconcli.ProtocolTELNET}).DefaultPort())
+	assert.Equal(t, rconcli.DefaultPortWebRCON, (&rconcli.Session{Type: rconcli.ProtocolWebRCON}).DefaultPort())
+	assert.Equal(t, 25575, (&rconcli.Session{Game: "minecraft"}).DefaultPort())
+	assert.Equal(t, 28016, (&rconcli.Session{Game: "rust", Type: rconcli.ProtocolWebRCON}).DefaultPort())
+	assert.Equal(t, rconcli.DefaultPortRCON, (&rconcli.Session{Game: "unknown"}).DefaultPort())
+}
+
+func TestSession_ResolveResponseEnd(t *testing.T) {
+	assert.Equal(t, rconcli.ResponseEndSingle, (&rconcli.Session{}).ResolveResponseEnd())
+	assert.Equal(t, rconcli.ResponseEndMirror, (&rconcli.Session{Game: "csgo"}).ResolveResponseEnd())
+	assert.Equal(t, rconcli.ResponseEndSentinel, (&rconcli.Session{Game: "minecraft"}).ResolveResponseEnd())
+	assert.Equal(t, rconcli.ResponseEndSingle, (&rconcli.Session{Game: "7dtd"}).ResolveResponseEnd())
+	assert.Equal(t, rconcli.ResponseEndSingle, (&rconcli.Session{Game: "zomboid"}).ResolveResponseEnd())
+	assert.Equal(t, rconcli.ResponseEndIdle,
+		(&rconcli.Session{Game: "csgo", ResponseEnd: rconcli.ResponseEndIdle}).ResolveResponseEnd())
+
+	assert.Equal(t, 250*time.Millisecond, (&rconcli.Session{}).ResolveResponseIdle())
+	assert.Equal(t, time.Second, (&rconcli.Session{ResponseIdle: time.Second}).ResolveResponseIdle())
+
+	// Idle timeout is limited by the shortest command timeout.
+	assert.Equal(t, 50*time.Millisecond, (&rconcli.Session{CommandTimeout: 100 * time.Millisecond}).ResolveResponseIdle())
+	assert.Equal(t, 50*time.Millisecond, (&rconcli.Session{
+		CommandTimeouts: map[string]time.Duration{"^save": 100 * time.Millisecond},
+	}).ResolveResponseIdle())
+}
+
+func TestSession_HasAddress(t *testing.T) {
+	assert.False(t, (&rconcli.Session{}).HasAddress())
+	assert.True(t, (&rconcli.Session{Address: "127.0.0.1"}).HasAddress())
+	assert.True(t, (&rconcli.Session{Addresses: []string{"127.0.0.1"}}).HasAddress())
+	assert.True(t, (&rconcli.Session{SRV: "_rcon._tcp.example.com"}).HasAddress())
+}
+
+func TestSession_ResolveTimeouts(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		ses := rconcli.Session{}
+		assert.Equal(t, rconcli.DefaultTimeout, ses.ResolveDialTimeout())
+		assert.Equal(t, rconcli.DefaultTimeout, ses.ResolveCommandTimeout("status"))
+		assert.Equal(t, rconcli.DefaultTimeout, ses.MaxCommandTimeout())
+	})
+
+	t.Run("timeout fallback", func(t *testing.T) {
+		ses := rconcli.Session{Timeout: 5 * time.Second, DialTimeout: 3 * time.Second}
+		assert.Equal(t, 3*time.Second, ses.ResolveDialTimeout())
+		assert.Equal(t, 5*time.Second, ses.ResolveCommandTimeout("status"))
+	})
+
+	t.Run("command overrides", func(t *testing.T) {
+		ses := rconcli.Session{
+			CommandTimeout: 10 * time.Second,
+			CommandTimeouts: map[string]time.Duration{
+				"^save":          time.Minute,
+				"^(save|backup)": 2 * time.Minute,
+			},
+		}
+		assert.Equal(t, 10*time.Second, ses.ResolveCommandTimeout("status"))
+		assert.Equal(t, 2*time.Minute, ses.ResolveCommandTimeout("save"))
+		assert.Equal(t, 2*time.Minute, ses.ResolveCommandTimeout("backup now"))
+		assert.Equal(t, 2*time.Minute, ses.MaxCommandTimeout())
+	})
+
+	t.Run("yaml durations", func(t *testing.T) {
+		fileName := "rcon-timeouts-test-local.yaml"
+		createFile(fileName, "rust:\n  dial_timeout: 3s\n  command_timeout: 10s\n  command_timeouts:\n    \"^save\": 2m\n")
+		defer os.Remove(fileName)
+
+		cfg, err := rconcli.LoadConfig(fileName)
+		assert.NoError(t, err)
+		assert.Equal(t, 3*time.Second, (*cfg)["rust"].DialTimeout)
+		assert.Equal(t, map[string]time.Duration{"^save": 2 * time.Minute}, (*cfg)["rust"].CommandTimeouts)
+	})
+}
+
+func TestSession_CommandLists(t *testing.T) {
+	t.Run("invalid regex", func(t *testing.T) {
+		cfg := rconcli.Config{"rust": rconcli.Session{Deny: []string{"("}}}
+		assert.ErrorIs(t, cfg.Validate(), rconcli.ErrConfigValidation)
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		ses := rconcli.Session{Deny: []string{"^wipe"}}
+		assert.True(t, ses.IsDenied("wipe all"))
+		assert.True(t, ses.IsDenied("  wipe"))
+		assert.False(t, ses.IsDenied("status"))
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		ses := rconcli.Session{Confirm: []string{"^kick"}}
+		assert.True(t, ses.NeedsConfirm("kick player"))
+		assert.False(t, ses.NeedsConfirm("quit"))
+	})
+
+	t.Run("protected", func(t *testing.T) {
+		ses := rconcli.Session{Confirm: []string{"^kick"}, Protected: true}
+		assert.True(t, ses.NeedsConfirm("kick player"))
+		assert.True(t, ses.NeedsConfirm("Quit"))
+		assert.True(t, ses.NeedsConfirm("shutdown 60"))
+		assert.False(t, ses.NeedsConfirm("status"))
+		assert.False(t, ses.NeedsConfirm("quitters"))
+	})
+}
+
+func TestSession_RoleAllows(t *testing.T) {
+	ses := rconcli.Session{Roles: map[string]rconcli.Role{
+		"moderator": {Allow: []string{"^(kick|ban|say) "}, Deny: []string{"^ban admin"}},
+		"observer":  {Deny: []string{"^(quit|kick|ban)"}},
+	}}
+
+	assert.True(t, ses.RoleAllows("", "quit"))
+	assert.True(t, ses.RoleAllows("moderator", "kick player"))
+	assert.False(t, ses.RoleAllows("moderator", "quit"))
+	assert.False(t, ses.RoleAllows("moderator", "ban admin"))
+	assert.True(t, ses.RoleAllows("observer", "status"))
+	assert.False(t, ses.RoleAllows("observer", "kick player"))
+	assert.False(t, ses.RoleAllows("admin", "status"))
+
+	// Compiled lists match the same commands.
+	assert.NoError(t, ses.Compile())
+	assert.True(t, ses.RoleAllows("moderator", "kick player"))
+	assert.False(t, ses.RoleAllows("moderator", "ban admin"))
+	assert.False(t, ses.RoleAllows("observer", "kick player"))
+}
+
+func TestSession_Compile(t *testing.T) {
+	ses := rconcli.Session{Deny: []string{"("}}
+	assert.ErrorContains(t, ses.Compile(), `deny regex "("`)
+
+	// Invalid lists of not compiled session fail closed.
+	ses.Deny = append(ses.Deny, "^wipe")
+	assert.True(t, ses.IsDenied("wipe"))
+	assert.True(t, ses.IsDenied("status"))
+
+	ses = rconcli.Session{Roles: map[string]rconcli.Role{"moderator": {Deny: []string{"^(quit"}}}}
+	assert.ErrorContains(t, ses.Compile(), `role moderator deny regex "^(quit"`)
+	assert.False(t, ses.RoleAllows("moderator", "quit"))
+	assert.False(t, ses.RoleAllows("moderator", "status"))
+	assert.False(t, ses.Roles["moderator"].Allowed("status"))
+
+	ses = rconcli.Session{CommandTimeouts: map[string]time.Duration{"^save": time.Minute}, Protected: true}
+	assert.NoError(t, ses.Compile())
+	assert.Equal(t, time.Minute, ses.ResolveCommandTimeout("save"))
+	assert.True(t, ses.NeedsConfirm("quit"))
+}
@@ -0,0 +1,102 @@
+package rconcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+)
+
+// ErrFollowNotSupported is returned when following is requested for
+// protocols without server messages.
+var ErrFollowNotSupported = errors.New("follow is supported only for web protocol")
+
+// Follow connects to the WebRCON server of the session and passes every
+// received message, e.g. logs and chat, to fn until the context is done or
+// fn returns an error. Follow returns nil when the context is done.
+// Addresses of the session are tried in order only when connecting.
+func Follow(ctx context.Context, ses *Session, fn func(message Message) error) error {
+	if ses.Type != ProtocolWebRCON {
+		return fmt.Errorf("%w, got %q", ErrFollowNotSupported, ses.Type)
+	}
+
+	conn, err := eachAddress(ctx, ses, func(ses *Session) (*followConn, error) {
+		return dialFollow(ctx, ses)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return err
+	}
+
+	// Blocked read is interrupted by closing the connection.
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+	defer conn.Close()
+
+	for {
+		message, err := conn.Read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if err = fn(newMessage(message)); err != nil {
+			return err
+		}
+	}
+}
+
+// followConn is WebRCON connection closing its tunnel.
+type followConn struct {
+	*webrcon.Conn
+	tunnel *Tunnel
+}
+
+// Close closes the connection and the tunnel.
+func (c *followConn) Close() error {
+	err := c.Conn.Close()
+	_ = c.tunnel.Close()
+
+	return err
+}
+
+// dialFollow connects to the WebRCON server of the session within the dial
+// timeout.
+func dialFollow(ctx context.Context, ses *Session) (*followConn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, ses.ResolveDialTimeout())
+	defer cancel()
+
+	tunnel, err := OpenTunnel(dialCtx, ses)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := webOptions(ses, tunnel)
+	if err != nil {
+		_ = tunnel.Close()
+
+		return nil, err
+	}
+
+	conn, err := webrcon.DialContext(dialCtx, ses.Address, ses.Password, options...)
+	if err != nil {
+		_ = tunnel.Close()
+
+		if ctx.Err() == nil && dialCtx.Err() != nil {
+			return nil, fmt.Errorf("%w after %s", ErrDialTimeout, ses.ResolveDialTimeout())
+		}
+
+		return nil, err
+	}
+
+	return &followConn{Conn: conn, tunnel: tunnel}, nil
+}
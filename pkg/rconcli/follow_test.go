@@ -0,0 +1,69 @@
+package rconcli_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/internal/webrcon"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollow(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	server, err := mock.NewServer(rconcli.ProtocolWebRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	ses := &rconcli.Session{Address: server.Addr(), Password: "password", Type: rconcli.ProtocolWebRCON}
+
+	// Test following stops without error when the context is done.
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := rconcli.Follow(ctx, ses, func(message rconcli.Message) error {
+			t.Errorf("unexpected message %v", message)
+
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		wrong := *ses
+		wrong.Password = "wrong"
+
+		err := rconcli.Follow(context.Background(), &wrong, func(rconcli.Message) error { return nil })
+		assert.ErrorIs(t, err, webrcon.ErrAuthFailed)
+		assert.ErrorIs(t, err, rconcli.ErrAuthFailed)
+	})
+
+	t.Run("not web", func(t *testing.T) {
+		err := rconcli.Follow(context.Background(), &rconcli.Session{Type: rconcli.ProtocolRCON}, nil)
+		assert.ErrorIs(t, err, rconcli.ErrFollowNotSupported)
+	})
+}
+
+func TestExecuteMessage(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	server, err := mock.NewServer(rconcli.ProtocolWebRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	client, err := rconcli.Dial(context.Background(), &rconcli.Session{
+		Address: server.Addr(), Password: "password", Type: rconcli.ProtocolWebRCON, WebIdentifier: 7,
+	})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	response, err := rconcli.ExecuteMessage(context.Background(), client, "help")
+	assert.NoError(t, err)
+	assert.Equal(t, rconcli.Message{Message: mock.DefaultResponse, Identifier: 7, Type: webrcon.TypeGeneric}, response)
+}
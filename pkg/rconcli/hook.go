@@ -0,0 +1,64 @@
+package rconcli
+
+import (
+	"strings"
+	"time"
+)
+
+// Entry is the executed command passed to hooks.
+type Entry struct {
+	Time     time.Time
+	Address  string
+	Command  string
+	Response string
+	Err      error
+	Duration time.Duration
+}
+
+// Hook is called after each command executed by the client.
+type Hook func(entry Entry)
+
+// hookedClient is Client calling hooks after each command.
+type hookedClient struct {
+	Client
+	address string
+	hooks   []Hook
+}
+
+// WithHooks returns the client calling hooks after each executed command.
+func WithHooks(client Client, address string, hooks ...Hook) Client {
+	return &hookedClient{Client: client, address: address, hooks: hooks}
+}
+
+// Execute executes the command and passes the result to hooks.
+func (c *hookedClient) Execute(command string) (string, error) {
+	start := time.Now()
+	response, err := c.Client.Execute(command)
+
+	entry := Entry{
+		Time:     start,
+		Address:  c.address,
+		Command:  command,
+		Response: response,
+		Err:      err,
+		Duration: time.Since(start),
+	}
+
+	for _, hook := range c.hooks {
+		hook(entry)
+	}
+
+	return response, err
+}
+
+// FileHook returns the hook writing commands and responses to the log file
+// in the same format as the CLI does. Write errors are passed to onError if
+// it is not nil.
+func FileHook(name string, onError func(err error)) Hook {
+	return func(entry Entry) {
+		err := WriteLog(name, entry.Address, entry.Command, strings.TrimSpace(entry.Response))
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package rconcli_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnknownCommand = errors.New("unknown command")
+
+// client is Client responding to status command.
+type client struct{}
+
+func (c *client) Execute(command string) (string, error) {
+	if command == "status" {
+		return "players : 0 (500 max)\n", nil
+	}
+
+	return "", errUnknownCommand
+}
+
+func (c *client) Close() error {
+	return nil
+}
+
+func TestWithHooks(t *testing.T) {
+	logName := "hook-test-local.log"
+	defer os.Remove(logName)
+
+	var entries []rconcli.Entry
+
+	hooked := rconcli.WithHooks(&client{}, "127.0.0.1:16260",
+		func(entry rconcli.Entry) { entries = append(entries, entry) },
+		rconcli.FileHook(logName, func(err error) { t.Error(err) }),
+	)
+
+	result, err := hooked.Execute("status")
+	assert.NoError(t, err)
+	assert.Equal(t, "players : 0 (500 max)\n", result)
+
+	_, err = hooked.Execute("help")
+	assert.ErrorIs(t, err, errUnknownCommand)
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "127.0.0.1:16260", entries[0].Address)
+		assert.Equal(t, "status", entries[0].Command)
+		assert.NoError(t, entries[0].Err)
+		assert.ErrorIs(t, entries[1].Err, errUnknownCommand)
+	}
+
+	records, err := rconcli.ReadLog(logName)
+	assert.NoError(t, err)
+
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "status", records[0].Request)
+		assert.Equal(t, "players : 0 (500 max)", records[0].Response)
+	}
+}
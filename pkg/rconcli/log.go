@@ -0,0 +1,198 @@
+package rconcli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayout is layout for convert time.Now to String.
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// DefaultLineFormat is format to log line record.
+const DefaultLineFormat = "[%s] %s: %s\n%s\n\n"
+
+// DefaultAuditFormat is format to log audit record of refused command.
+const DefaultAuditFormat = "[%s] AUDIT %s role=%s: %s\n%s\n\n"
+
+// ErrEmptyFileName is returned when trying to open file with empty name.
+var ErrEmptyFileName = errors.New("empty file name")
+
+// recordHeader matches the first line of the record written by WriteLog.
+var recordHeader = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})] (\S+): (.*)$`)
+
+// auditHeader matches the first line of the record written by WriteAudit.
+var auditHeader = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})] AUDIT \S+ `)
+
+// LogRecord is a request and response pair saved to log file.
+type LogRecord struct {
+	Time     time.Time
+	Address  string
+	Request  string
+	Response string
+}
+
+// OpenLogFile opens file for append strings. Creates file if file not exist.
+func OpenLogFile(name string) (*os.File, error) {
+	if name == "" {
+		return nil, ErrEmptyFileName
+	}
+
+	var file *os.File
+
+	switch _, err := os.Stat(name); {
+	case err == nil:
+		const perm = 0o666
+
+		file, err = os.OpenFile(name, os.O_APPEND|os.O_WRONLY, perm)
+		if err != nil {
+			return file, fmt.Errorf("open: %w", err)
+		}
+	case os.IsNotExist(err):
+		dir := filepath.Dir(name)
+		if _, err = os.Stat(dir); os.IsNotExist(err) {
+			const perm = 0o766
+
+			if err = os.MkdirAll(dir, perm); err != nil {
+				return file, fmt.Errorf("create directory: %w", err)
+			}
+		}
+
+		file, err = os.Create(name)
+		if err != nil {
+			return file, fmt.Errorf("create: %w", err)
+		}
+	}
+
+	return file, nil
+}
+
+// WriteLog saves request and response to log file.
+func WriteLog(name string, address string, request string, response string) error {
+	// Disable logging if log file name is empty.
+	if name == "" {
+		return nil
+	}
+
+	file, err := OpenLogFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line := fmt.Sprintf(DefaultLineFormat, time.Now().Format(DefaultTimeLayout), address, request, response)
+	if _, err = file.WriteString(line); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// WriteAudit saves refused command with the reason to log file. Role is
+// written as "-" if it is empty.
+func WriteAudit(name string, address string, role string, command string, reason string) error {
+	// Disable logging if log file name is empty.
+	if name == "" {
+		return nil
+	}
+
+	if role == "" {
+		role = "-"
+	}
+
+	file, err := OpenLogFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line := fmt.Sprintf(DefaultAuditFormat, time.Now().Format(DefaultTimeLayout), address, role, command, reason)
+	if _, err = file.WriteString(line); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// ReadLog opens log file and parses records from it.
+func ReadLog(name string) ([]LogRecord, error) {
+	if name == "" {
+		return nil, ErrEmptyFileName
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer file.Close()
+
+	return ParseLog(file)
+}
+
+// ParseLog reads records in DefaultLineFormat from r. Lines which do not start
+// with a record header are treated as a response of the previous record.
+// Audit records are skipped.
+func ParseLog(r io.Reader) ([]LogRecord, error) {
+	var (
+		records  []LogRecord
+		response []string
+		audit    bool
+	)
+
+	flush := func() {
+		if len(records) != 0 && !audit {
+			records[len(records)-1].Response = strings.TrimRight(strings.Join(response, "\n"), "\n")
+		}
+
+		response = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), math.MaxInt32)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if auditHeader.MatchString(line) {
+			flush()
+
+			audit = true
+
+			continue
+		}
+
+		matches := recordHeader.FindStringSubmatch(line)
+		if matches == nil {
+			if len(records) != 0 && !audit {
+				response = append(response, line)
+			}
+
+			continue
+		}
+
+		t, err := time.ParseInLocation(DefaultTimeLayout, matches[1], time.Local)
+		if err != nil {
+			return records, fmt.Errorf("parse time: %w", err)
+		}
+
+		flush()
+
+		audit = false
+		records = append(records, LogRecord{Time: t, Address: matches[2], Request: matches[3]})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("read: %w", err)
+	}
+
+	flush()
+
+	return records, nil
+}
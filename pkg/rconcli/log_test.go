@@ -0,0 +1,134 @@
+package rconcli_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenLogFile(t *testing.T) {
+	logDir := "temp"
+	logName := "tmpfile.log"
+	logPath := logDir + "/" + logName
+
+	// Test empty log file name.
+	t.Run("empty file name", func(t *testing.T) {
+		file, err := rconcli.OpenLogFile("")
+		assert.Nil(t, file)
+		assert.EqualError(t, err, "empty file name")
+	})
+
+	// Positive test create new log file.
+	t.Run("create new log file", func(t *testing.T) {
+		os.Mkdir(logDir, 0700)
+		defer os.RemoveAll(logDir)
+
+		file, err := rconcli.OpenLogFile(logPath)
+		assert.NotNil(t, file)
+		assert.NoError(t, err)
+	})
+}
+
+func TestWriteLog(t *testing.T) {
+	logName := "tmpfile.log"
+
+	address := "127.0.0.1:16200"
+	command := "players"
+	result := `Players connected (2):
+-admin
+-testuser`
+
+	defer os.Remove(logName)
+
+	// Test skip log. No logs is available.
+	t.Run("skip log", func(t *testing.T) {
+		err := rconcli.WriteLog("", address, command, result)
+		assert.NoError(t, err)
+	})
+
+	// Test create log file.
+	t.Run("create log file", func(t *testing.T) {
+		err := rconcli.WriteLog(logName, address, command, result)
+		assert.NoError(t, err)
+	})
+
+	// Test append to log file.
+	t.Run("append to log file", func(t *testing.T) {
+		err := rconcli.WriteLog(logName, address, command, result)
+		assert.NoError(t, err)
+	})
+}
+
+func TestReadLog(t *testing.T) {
+	logName := "tmpfile.log"
+
+	address := "127.0.0.1:16200"
+	result := `Players connected (2):
+-admin
+
+-testuser`
+
+	defer os.Remove(logName)
+
+	// Test empty log file name.
+	t.Run("empty file name", func(t *testing.T) {
+		records, err := rconcli.ReadLog("")
+		assert.Nil(t, records)
+		assert.EqualError(t, err, "empty file name")
+	})
+
+	// Positive test read records written by Write.
+	t.Run("read records", func(t *testing.T) {
+		assert.NoError(t, rconcli.WriteLog(logName, address, "players", result))
+		assert.NoError(t, rconcli.WriteLog(logName, address, "save", ""))
+
+		records, err := rconcli.ReadLog(logName)
+		assert.NoError(t, err)
+
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, address, records[0].Address)
+			assert.Equal(t, "players", records[0].Request)
+			assert.Equal(t, result, records[0].Response)
+			assert.Equal(t, "save", records[1].Request)
+			assert.Equal(t, "", records[1].Response)
+			assert.False(t, records[1].Time.IsZero())
+		}
+	})
+}
+
+func TestWriteAudit(t *testing.T) {
+	logName := "tmpfile-audit.log"
+	address := "127.0.0.1:16200"
+
+	defer os.Remove(logName)
+
+	// Test skip log. No logs is available.
+	t.Run("skip log", func(t *testing.T) {
+		err := rconcli.WriteAudit("", address, "moderator", "quit", "forbidden")
+		assert.NoError(t, err)
+	})
+
+	// Positive test audit records are skipped by Read.
+	t.Run("read skips audit", func(t *testing.T) {
+		assert.NoError(t, rconcli.WriteLog(logName, address, "players", "Players connected (0):"))
+		assert.NoError(t, rconcli.WriteAudit(logName, address, "moderator", "quit", "command is not allowed for role"))
+		assert.NoError(t, rconcli.WriteAudit(logName, address, "", "wipe", "command is denied"))
+		assert.NoError(t, rconcli.WriteLog(logName, address, "save", "Saved"))
+
+		data, err := os.ReadFile(logName)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "AUDIT "+address+" role=moderator: quit\ncommand is not allowed for role\n")
+		assert.Contains(t, string(data), "AUDIT "+address+" role=-: wipe\n")
+
+		records, err := rconcli.ReadLog(logName)
+		assert.NoError(t, err)
+
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, "Players connected (0):", records[0].Response)
+			assert.Equal(t, "save", records[1].Request)
+			assert.Equal(t, "Saved", records[1].Response)
+		}
+	})
+}
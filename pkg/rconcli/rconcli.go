@@ -0,0 +1,34 @@
+// Package rconcli provides the public API of rcon-cli for Go programs. It
+// resolves sessions from configuration files, dials remote servers over
+// RCON, TELNET and WebRCON protocols behind one Client interface and allows
+// to hook executed commands for logging.
+package rconcli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownEnv is returned when environment is not found in the
+// configuration file.
+var ErrUnknownEnv = errors.New("unknown environment")
+
+// LoadSession returns the session of the environment from the configuration
+// file. Default environment is used if env is empty.
+func LoadSession(name string, env string) (*Session, error) {
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if env == "" {
+		env = DefaultConfigEnv
+	}
+
+	ses, ok := (*cfg)[env]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEnv, env)
+	}
+
+	return &ses, nil
+}
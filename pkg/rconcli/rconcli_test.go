@@ -0,0 +1,40 @@
+package rconcli_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSession(t *testing.T) {
+	fileName := "rconcli-test-local.yaml"
+	createFile(fileName, "default:\n  address: \"127.0.0.1:16260\"\n  password: \"password\"\n"+
+		"rust:\n  address: \"127.0.0.1:28016\"\n  password: \"password\"\n  type: \"web\"\n")
+	defer os.Remove(fileName)
+
+	t.Run("file not exists", func(t *testing.T) {
+		ses, err := rconcli.LoadSession("nonexist.yaml", "")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, ses)
+	})
+
+	t.Run("unknown env", func(t *testing.T) {
+		ses, err := rconcli.LoadSession(fileName, "zomboid")
+		assert.ErrorIs(t, err, rconcli.ErrUnknownEnv)
+		assert.Nil(t, ses)
+	})
+
+	t.Run("default env", func(t *testing.T) {
+		ses, err := rconcli.LoadSession(fileName, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:16260", ses.Address)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		ses, err := rconcli.LoadSession(fileName, "rust")
+		assert.NoError(t, err)
+		assert.Equal(t, rconcli.ProtocolWebRCON, ses.Type)
+	})
+}
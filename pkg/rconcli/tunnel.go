@@ -0,0 +1,125 @@
+package rconcli
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/gorcon/rcon-cli/internal/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrUnsupportedTunnelScheme is returned when SOCKS5 proxy or SSH bastion
+// of the session has an unsupported URL scheme.
+var ErrUnsupportedTunnelScheme = transport.ErrUnsupportedScheme
+
+// Forwarder accepts a single local connection and forwards it to the remote
+// server through the tunnel and TLS. It allows clients dialing plain TCP,
+// e.g. interactive TELNET, to connect through them.
+type Forwarder struct {
+	forwarder *transport.Forwarder
+}
+
+// Addr returns the local address to connect to.
+func (f *Forwarder) Addr() string {
+	return f.forwarder.Addr()
+}
+
+// Close stops listening and closes forwarded connections.
+func (f *Forwarder) Close() error {
+	return f.forwarder.Close()
+}
+
+// Tunnel is the route to remote servers through SOCKS5 proxy and SSH bastion
+// of the session. It is opened once and can be reused by connections, e.g.
+// by reconnects in interactive mode. Tunnel of the session without proxy and
+// bastion dials remote servers directly.
+type Tunnel struct {
+	dial transport.DialFunc
+	ssh  *ssh.Client
+	// direct is true if the session has neither proxy nor bastion.
+	direct bool
+}
+
+// OpenTunnel connects to SOCKS5 proxy and SSH bastion of the session within
+// the session dial timeout. The bastion is connected through the proxy if
+// both are set.
+func OpenTunnel(ctx context.Context, ses *Session) (*Tunnel, error) {
+	tunnel := &Tunnel{dial: transport.Direct, direct: ses.Proxy == "" && ses.Via == ""}
+
+	if ses.Proxy != "" {
+		dial, err := transport.SOCKS5(ses.Proxy, tunnel.dial)
+		if err != nil {
+			return nil, err
+		}
+
+		tunnel.dial = dial
+	}
+
+	if ses.Via != "" {
+		dialCtx, cancel := context.WithTimeout(ctx, ses.ResolveDialTimeout())
+		defer cancel()
+
+		opts := transport.SSHOptions{KeyFile: ses.ViaKey, KnownHostsFile: ses.ViaKnownHosts}
+
+		client, err := transport.SSH(dialCtx, ses.Via, opts, tunnel.dial)
+		if err != nil {
+			if ctx.Err() == nil && dialCtx.Err() != nil {
+				return nil, fmt.Errorf("%w after %s", ErrDialTimeout, ses.ResolveDialTimeout())
+			}
+
+			return nil, wrapAuthError(err)
+		}
+
+		tunnel.ssh = client
+		tunnel.dial = transport.SSHDial(client)
+	}
+
+	return tunnel, nil
+}
+
+// DialContext connects to the address through the tunnel.
+func (t *Tunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return t.dial(ctx, network, address)
+}
+
+// Forward starts Forwarder to the remote server of the session through
+// the tunnel. Connections are wrapped in TLS if it is enabled. Addresses of
+// the session are tried in order, the forwarder connects to the first one
+// accepting connections.
+func (t *Tunnel) Forward(ctx context.Context, ses *Session) (*Forwarder, error) {
+	return eachAddress(ctx, ses, func(ses *Session) (*Forwarder, error) {
+		return t.forward(ctx, ses)
+	})
+}
+
+// forward starts Forwarder to the address of the session.
+func (t *Tunnel) forward(ctx context.Context, ses *Session) (*Forwarder, error) {
+	dial := t.dial
+
+	if ses.TLSEnabled() {
+		cfg, err := tlsConfig(ses, ses.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		dial = transport.WithTLS(dial, cfg)
+	}
+
+	forwarder, err := transport.Forward(ctx, dial, ses.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Forwarder{forwarder: forwarder}, nil
+}
+
+// Close closes connection to SSH bastion. Connections through the tunnel
+// are closed too.
+func (t *Tunnel) Close() error {
+	if t.ssh != nil {
+		return t.ssh.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package rconcli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorcon/rcon-cli/internal/mock"
+	"github.com/gorcon/rcon-cli/internal/transport/transporttest"
+	"github.com/gorcon/rcon-cli/pkg/rconcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDial_Tunnel(t *testing.T) {
+	responses, err := mock.NewResponses("")
+	assert.NoError(t, err)
+
+	bastion, err := transporttest.NewBastion()
+	assert.NoError(t, err)
+	defer bastion.Close()
+
+	proxy, err := transporttest.NewSOCKS5("rcon", "secret")
+	assert.NoError(t, err)
+	defer proxy.Close()
+
+	for _, protocol := range []string{rconcli.ProtocolRCON, rconcli.ProtocolTELNET, rconcli.ProtocolWebRCON} {
+		protocol := protocol
+
+		server, err := mock.NewServer(protocol, "127.0.0.1:0", "password", responses)
+		assert.NoError(t, err)
+		defer server.Close()
+
+		ses := &rconcli.Session{
+			Address:       server.Addr(),
+			Password:      "password",
+			Type:          protocol,
+			Via:           bastion.URL(),
+			ViaKey:        bastion.KeyFile,
+			ViaKnownHosts: bastion.KnownHostsFile,
+		}
+
+		t.Run(protocol+" via ssh", func(t *testing.T) {
+			client, err := rconcli.Dial(context.Background(), ses)
+			assert.NoError(t, err)
+			defer client.Close()
+
+			result, err := client.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, mock.DefaultResponse, result)
+		})
+
+		t.Run(protocol+" via proxy", func(t *testing.T) {
+			proxied := &rconcli.Session{Address: server.Addr(), Password: "password", Type: protocol, Proxy: proxy.URL()}
+
+			client, err := rconcli.Dial(context.Background(), proxied)
+			assert.NoError(t, err)
+			defer client.Close()
+
+			result, err := client.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, mock.DefaultResponse, result)
+		})
+	}
+
+	server, err := mock.NewServer(rconcli.ProtocolRCON, "127.0.0.1:0", "password", responses)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	ses := &rconcli.Session{
+		Address:       server.Addr(),
+		Password:      "password",
+		Via:           bastion.URL(),
+		ViaKey:        bastion.KeyFile,
+		ViaKnownHosts: bastion.KnownHostsFile,
+	}
+
+	// Test the tunnel is reused by connections and is not closed with them.
+	t.Run("tunnel reuse", func(t *testing.T) {
+		connections := bastion.Connections()
+
+		tunnel, err := rconcli.OpenTunnel(context.Background(), ses)
+		assert.NoError(t, err)
+		defer tunnel.Close()
+
+		for i := 0; i < 3; i++ {
+			client, err := rconcli.DialTunnel(context.Background(), ses, tunnel)
+			assert.NoError(t, err)
+
+			result, err := client.Execute("help")
+			assert.NoError(t, err)
+			assert.Equal(t, mock.DefaultResponse, result)
+
+			assert.NoError(t, client.Close())
+		}
+
+		assert.Equal(t, connections+1, bastion.Connections())
+	})
+
+	t.Run("unknown bastion", func(t *testing.T) {
+		unknown := *ses
+		unknown.ViaKnownHosts = bastion.KeyFile + ".missing"
+
+		client, err := rconcli.Dial(context.Background(), &unknown)
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+}